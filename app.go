@@ -7,9 +7,15 @@ import (
 	"log"
 	"os"
 	"strings"
+	"time"
 
+	"oh-my-rime-cli/internal/cli"
+	"oh-my-rime-cli/internal/config"
 	"oh-my-rime-cli/internal/constants"
+	"oh-my-rime-cli/internal/deploy"
 	"oh-my-rime-cli/internal/downloader"
+	"oh-my-rime-cli/internal/releases"
+	"oh-my-rime-cli/internal/rimelog"
 	"oh-my-rime-cli/internal/system"
 	"oh-my-rime-cli/internal/updater"
 
@@ -92,62 +98,556 @@ func (a *App) SelectDirectory() string {
 	return dir
 }
 
-// UpdateAction performs the update download and extract
-func (a *App) UpdateAction(actionType string, targetDir string, customUrl string) map[string]interface{} {
+// UpdateAction performs the update download and extract.
+// customUrl 仅用于 custom 更新，modelName 为 model 更新所选模型的文件名。
+// 每次只更新一个目录，同时更新多个目录（只下载一次、分别备份）由命令行的 batch 提供
+func (a *App) UpdateAction(actionType string, targetDir string, customUrl string, modelName string) map[string]interface{} {
 	if targetDir == "" && system.DetectOS() == "Windows_NT" {
 		targetDir = system.GetWindowsTargetDir()
 	} else if targetDir == "" {
 		return map[string]interface{}{"success": false, "error": "请选择目标目录"}
 	}
 
+	cfg, err := loadConfig()
+	if err != nil {
+		runtime.EventsEmit(a.ctx, "log", err.Error()+"\n")
+		return map[string]interface{}{"success": false, "error": err.Error()}
+	}
+
 	progressCallback := a.getProgressCallback()
-	var err error
+	hooks := configHooks(cfg)
 
+	model := findWanXiangModel(cfg.Models(), modelName)
+	var operation, source string
 	switch actionType {
 	case "main":
-		zip := downloader.DownloadWithCallback(constants.OhMyRimeRepo, progressCallback)
-		if zip != nil {
-			err = updater.UpdateMainScheme(zip, targetDir)
-		} else {
-			err = fmt.Errorf("下载主方案失败")
-		}
+		operation, source = updater.OperationMainScheme, cfg.SchemeURL()
 	case "model":
-		gram := downloader.DownloadWithCallback(constants.WanXiangGRA, progressCallback)
-		if gram != nil {
-			err = updater.UpdateModel(gram, targetDir)
-		} else {
-			err = fmt.Errorf("下载万象模型失败")
-		}
+		operation, source = updater.OperationModel, model.URL
 	case "dict":
-		zip := downloader.DownloadWithCallback(constants.OhMyRimeRepo, progressCallback)
-		if zip != nil {
-			err = updater.UpdateDict(zip, targetDir)
-		} else {
-			err = fmt.Errorf("下载万象词库失败")
-		}
+		operation, source = updater.OperationDict, cfg.SchemeURL()
 	case "custom":
-		data := downloader.DownloadWithCallback(customUrl, progressCallback)
-		if data != nil {
-			if strings.HasSuffix(strings.ToLower(customUrl), ".zip") {
-				err = updater.UpdateMainScheme(data, targetDir)
+		if operation, err = updater.CustomOperation(customUrl); err != nil {
+			runtime.EventsEmit(a.ctx, "log", err.Error()+"\n")
+			return map[string]interface{}{"success": false, "error": err.Error()}
+		}
+		source = cfg.ResolveURL(customUrl)
+	default:
+		return map[string]interface{}{"success": false, "error": "未知的更新类型"}
+	}
+
+	// 固定了版本时只安装该版本，已安装则跳过
+	if actionType != "custom" {
+		asset := operation
+		if actionType == "model" {
+			asset = updater.ModelAsset(model.FileName)
+		}
+		pinned, pin, pinErr := pinnedSource(targetDir, asset, source)
+		if pinErr != nil {
+			runtime.EventsEmit(a.ctx, "log", pinErr.Error()+"\n")
+			return map[string]interface{}{"success": false, "error": pinErr.Error()}
+		}
+		if pinned == "" {
+			runtime.EventsEmit(a.ctx, "log", "📌 已固定在 "+pin+" 且已安装该版本，跳过更新\n")
+			return map[string]interface{}{"success": true, "pinned": pin}
+		}
+		source = pinned
+	}
+
+	err = updater.RunPreDownloadHooks(hooks, operation, targetDir, source)
+	if err == nil {
+		opts := updateOptions(cfg, hooks, source)
+		// 记录安装的版本，用于检查更新
+		if remote, probeErr := downloader.Probe(source); probeErr == nil {
+			opts.Version, opts.ETag, opts.ReleaseDate = remote.Version, remote.ETag, remote.LastModified
+		}
+		var data []byte
+		switch actionType {
+		case "main":
+			if data = downloader.DownloadWithCallback(source, progressCallback); data != nil {
+				err = updater.UpdateMainSchemeWithOptions(data, targetDir, opts)
+			} else {
+				err = fmt.Errorf("下载主方案失败")
+			}
+		case "model":
+			a.logInstalledModels(targetDir)
+			if data = downloader.DownloadWithCallback(source, progressCallback); data != nil {
+				opts.ModelName = model.FileName
+				err = updater.UpdateModelWithOptions(data, targetDir, opts)
+			} else {
+				err = fmt.Errorf("下载万象模型失败")
+			}
+		case "dict":
+			if data = downloader.DownloadZipEntries(source, updater.IsDictEntry, progressCallback); data != nil {
+				err = updater.UpdateDictWithOptions(data, targetDir, opts)
 			} else {
-				err = updater.UpdateModel(data, targetDir)
+				err = fmt.Errorf("下载万象词库失败")
 			}
+		case "custom":
+			var fileName string
+			if data, fileName = downloader.DownloadFile(source, progressCallback); data == nil {
+				err = fmt.Errorf("下载自定义资源失败")
+			} else if operation == updater.OperationMainScheme {
+				err = updater.UpdateMainSchemeWithOptions(data, targetDir, opts)
+			} else if opts.ModelName, err = cli.ModelFileName("", fileName); err == nil {
+				err = updater.UpdateModelWithOptions(data, targetDir, opts)
+			}
+		}
+		if data == nil {
+			// 下载失败时更新尚未开始，由这里执行 post-failure 钩子
+			updater.RunFailureHooks(hooks, operation, targetDir, source, err)
+		}
+	}
+
+	if err != nil {
+		runtime.EventsEmit(a.ctx, "log", err.Error()+"\n")
+		return map[string]interface{}{"success": false, "error": err.Error()}
+	}
+
+	return map[string]interface{}{"success": true}
+}
+
+// pinnedSource 返回目标目录固定的版本及其下载地址，没有固定时原样返回 source；
+// 已安装固定的版本时返回的地址为空
+func pinnedSource(targetDir, asset, source string) (string, string, error) {
+	pins, err := updater.Pins(targetDir)
+	if err != nil {
+		return "", "", fmt.Errorf("读取固定版本失败: %v", err)
+	}
+	pin := pins[asset]
+	if pin == "" {
+		return source, "", nil
+	}
+	if installed, err := updater.InstalledVersions(targetDir); err == nil && installed[asset].Version == pin {
+		return "", pin, nil
+	}
+	pinned, err := releases.AssetURL(source, pin)
+	if err != nil {
+		return "", "", err
+	}
+	return pinned, pin, nil
+}
+
+// GetPendingUpdates 返回上次被中断的更新记录
+func (a *App) GetPendingUpdates() []map[string]interface{} {
+	pending, err := updater.PendingUpdates()
+	if err != nil {
+		runtime.LogErrorf(a.ctx, "检查未完成的更新失败: %v", err)
+		return nil
+	}
+
+	result := make([]map[string]interface{}, 0, len(pending))
+	for _, update := range pending {
+		result = append(result, map[string]interface{}{
+			"operation":     update.Operation,
+			"operationName": update.OperationName(),
+			"targetDir":     update.TargetDir,
+			"backupDir":     update.BackupDir,
+			"startedAt":     update.StartedAt.Format("2006-01-02 15:04:05"),
+			"writtenCount":  len(update.Written),
+		})
+	}
+	return result
+}
+
+// ResolvePendingUpdate 处理中断的更新：rollback 回滚、resume 继续、discard 忽略
+func (a *App) ResolvePendingUpdate(targetDir string, action string) map[string]interface{} {
+	update, err := findPendingUpdate(targetDir)
+	if err != nil {
+		return map[string]interface{}{"success": false, "error": err.Error()}
+	}
+
+	switch action {
+	case "rollback":
+		err = updater.RollbackPendingUpdate(update)
+	case "resume":
+		var cfg *config.Config
+		if cfg, err = loadConfig(); err != nil {
+			break
+		}
+		data := cli.DownloadForResume(update, a.getProgressCallback())
+		if data != nil {
+			err = updater.ResumePendingUpdate(update, data, updateOptions(cfg, configHooks(cfg), cli.ResumeSource(update)))
 		} else {
-			err = fmt.Errorf("下载自定义资源失败")
+			err = fmt.Errorf("下载失败，更新记录已保留")
 		}
+	case "discard":
+		err = updater.DiscardPendingUpdate(update)
 	default:
-		err = fmt.Errorf("未知的更新类型")
+		err = fmt.Errorf("未知的处理方式: %s", action)
 	}
 
 	if err != nil {
 		runtime.EventsEmit(a.ctx, "log", err.Error()+"\n")
 		return map[string]interface{}{"success": false, "error": err.Error()}
 	}
+	return map[string]interface{}{"success": true}
+}
+
+func findPendingUpdate(targetDir string) (*updater.PendingUpdate, error) {
+	pending, err := updater.PendingUpdates()
+	if err != nil {
+		return nil, err
+	}
+	for _, update := range pending {
+		if update.TargetDir == targetDir {
+			return update, nil
+		}
+	}
+	return nil, fmt.Errorf("未找到该目录的更新记录: %s", targetDir)
+}
+
+// findWanXiangModel 按文件名查找万象模型版本，未找到时返回默认模型
+func findWanXiangModel(models []constants.WanXiangModel, fileName string) constants.WanXiangModel {
+	for _, model := range models {
+		if model.FileName == fileName {
+			return model
+		}
+	}
+	return models[0]
+}
+
+// logInstalledModels 在日志中列出目标目录已安装的模型
+func (a *App) logInstalledModels(targetDir string) {
+	models, err := updater.ListModels(targetDir)
+	if err != nil || len(models) == 0 {
+		return
+	}
+	var lines strings.Builder
+	lines.WriteString("已安装的模型:\n")
+	for _, model := range models {
+		lines.WriteString(fmt.Sprintf("  %s (%s)\n", model.Name, downloader.FormatBytes(model.Size)))
+	}
+	runtime.EventsEmit(a.ctx, "log", lines.String())
+}
+
+// GetGrammarSchemas 返回目标目录中的方案与之前已启用语法模型的方案，供用户选择要启用的方案
+func (a *App) GetGrammarSchemas(targetDir string) map[string]interface{} {
+	if targetDir == "" && system.DetectOS() == "Windows_NT" {
+		targetDir = system.GetWindowsTargetDir()
+	} else if targetDir == "" {
+		return map[string]interface{}{"success": false, "error": "请选择目标目录"}
+	}
+
+	available, err := updater.ListSchemas(targetDir)
+	if err != nil {
+		return map[string]interface{}{"success": false, "error": err.Error()}
+	}
+	patched, err := updater.GrammarPatchedSchemas(targetDir)
+	if err != nil {
+		runtime.EventsEmit(a.ctx, "log", "读取语法模型修改记录失败: "+err.Error()+"\n")
+	}
+	return map[string]interface{}{"success": true, "schemas": append([]string{}, available...), "patched": append([]string{}, patched...)}
+}
 
+// SetGrammarEnabled 在所选方案中启用语法模型，或撤销之前的修改
+func (a *App) SetGrammarEnabled(targetDir string, modelName string, schemas []string, enabled bool) map[string]interface{} {
+	if targetDir == "" && system.DetectOS() == "Windows_NT" {
+		targetDir = system.GetWindowsTargetDir()
+	} else if targetDir == "" {
+		return map[string]interface{}{"success": false, "error": "请选择目标目录"}
+	}
+
+	var err error
+	if enabled {
+		if len(schemas) == 0 {
+			return map[string]interface{}{"success": false, "error": "请选择要启用语法模型的方案"}
+		}
+		err = updater.EnableGrammar(targetDir, findWanXiangModel(constants.WanXiangModels, modelName).FileName, schemas)
+	} else {
+		err = updater.DisableGrammar(targetDir)
+	}
+	if err != nil {
+		runtime.EventsEmit(a.ctx, "log", err.Error()+"\n")
+		return map[string]interface{}{"success": false, "error": err.Error()}
+	}
 	return map[string]interface{}{"success": true}
 }
 
+// Redeploy 重新部署目标目录所属的输入法前端
+func (a *App) Redeploy(targetDir string) map[string]interface{} {
+	if targetDir == "" && system.DetectOS() == "Windows_NT" {
+		targetDir = system.GetWindowsTargetDir()
+	}
+	command, err := deploy.Deploy(deploy.FrontendForDir(system.ExpandHomeDir(targetDir)))
+	if err != nil {
+		runtime.EventsEmit(a.ctx, "log", err.Error()+"\n")
+		return map[string]interface{}{"success": false, "error": err.Error()}
+	}
+	runtime.EventsEmit(a.ctx, "log", "已重新部署 Rime: "+command.String()+"\n")
+	return map[string]interface{}{"success": true, "command": command.String()}
+}
+
+// InspectRimeLog 检查 Rime 日志中 since（Unix 秒，0 表示不限）之后的方案、词库与 Lua 问题。
+// targetDir 为空时检查所有前端的日志
+func (a *App) InspectRimeLog(targetDir string, since int64) map[string]interface{} {
+	var sinceTime time.Time
+	if since > 0 {
+		sinceTime = time.Unix(since, 0)
+	}
+	frontend := deploy.FrontendUnknown
+	if targetDir != "" {
+		frontend = deploy.FrontendForDir(system.ExpandHomeDir(targetDir))
+	}
+
+	report, err := rimelog.Inspect(frontend, sinceTime)
+	if err != nil {
+		runtime.EventsEmit(a.ctx, "log", err.Error()+"\n")
+		return map[string]interface{}{"success": false, "error": err.Error()}
+	}
+
+	var lines strings.Builder
+	for _, path := range report.LogFiles {
+		lines.WriteString("Rime 日志: " + path + "\n")
+	}
+	for _, entry := range report.Entries {
+		lines.WriteString(entry.String() + "\n")
+	}
+	if lines.Len() > 0 {
+		runtime.EventsEmit(a.ctx, "log", lines.String())
+	}
+	return map[string]interface{}{
+		"success":  true,
+		"logFiles": report.LogFiles,
+		"entries":  report.Entries,
+	}
+}
+
+// GetModelVariants 返回可下载的万象模型版本
+func (a *App) GetModelVariants() []map[string]interface{} {
+	var variants []map[string]interface{}
+	for _, model := range constants.WanXiangModels {
+		variants = append(variants, map[string]interface{}{
+			"name":     model.Name,
+			"fileName": model.FileName,
+		})
+	}
+	return variants
+}
+
+// GetTargetCandidates 返回配置文件中的默认目录；未配置时返回检测到的 Rime 用户目录（按可能性排序），
+// 未检测到时返回全部候选目录，与命令行一致
+func (a *App) GetTargetCandidates() []map[string]interface{} {
+	cfg, err := loadConfig()
+	if err != nil {
+		runtime.EventsEmit(a.ctx, "log", err.Error()+"\n")
+		return nil
+	}
+	if targets := configTargets(cfg); len(targets) > 0 {
+		return targets
+	}
+
+	candidates, _, err := cli.TargetCandidates()
+	if err != nil {
+		runtime.EventsEmit(a.ctx, "log", err.Error()+"\n")
+		return nil
+	}
+	var result []map[string]interface{}
+	for _, candidate := range candidates {
+		result = append(result, map[string]interface{}{
+			"label":    candidate.Label,
+			"frontend": candidate.Frontend,
+			"dir":      candidate.Dir,
+			"reasons":  candidate.Reasons,
+		})
+	}
+	return result
+}
+
+// CheckUpdates 检查目标目录中的方案、词库与模型是否有新版本
+func (a *App) CheckUpdates(targetDir string) map[string]interface{} {
+	if targetDir == "" {
+		return map[string]interface{}{"success": false, "error": "请选择目标目录"}
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return map[string]interface{}{"success": false, "error": err.Error()}
+	}
+	checks, err := cli.NewUpdateChecker(cfg).Check(targetDir)
+	if err != nil {
+		return map[string]interface{}{"success": false, "error": err.Error()}
+	}
+	var result []map[string]interface{}
+	for _, check := range checks {
+		item := map[string]interface{}{
+			"asset":     check.Asset,
+			"name":      check.Name,
+			"status":    string(check.Status),
+			"installed": check.Installed,
+			"latest":    check.Latest,
+			"summary":   check.Summary(),
+			"pinned":    check.Pinned,
+			"error":     check.Error,
+		}
+		if !check.ReleaseDate.IsZero() {
+			item["releaseDate"] = check.ReleaseDate.Local().Format("2006-01-02")
+		}
+		if check.Size > 0 {
+			item["size"] = downloader.FormatBytes(check.Size)
+		}
+		result = append(result, item)
+	}
+	return map[string]interface{}{"success": true, "checks": result}
+}
+
+// GetChangelog 返回目标目录从已安装版本更新到最新（或固定）版本所经过的发布说明，无法连接发布源时使用缓存。
+// actionType 为 main、model 或 dict，模型更新时 modelFileName 为所选模型的文件名
+func (a *App) GetChangelog(actionType string, targetDir string, modelFileName string) map[string]interface{} {
+	if targetDir == "" && system.DetectOS() == "Windows_NT" {
+		targetDir = system.GetWindowsTargetDir()
+	} else if targetDir == "" {
+		return map[string]interface{}{"success": false, "error": "请选择目标目录"}
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return map[string]interface{}{"success": false, "error": err.Error()}
+	}
+
+	var asset, source string
+	switch actionType {
+	case "main":
+		asset, source = updater.OperationMainScheme, cfg.SchemeURL()
+	case "dict":
+		asset, source = updater.OperationDict, cfg.SchemeURL()
+	case "model":
+		model := findWanXiangModel(cfg.Models(), modelFileName)
+		asset, source = updater.ModelAsset(model.FileName), model.URL
+	default:
+		return map[string]interface{}{"success": false, "error": "该更新类型没有发布说明"}
+	}
+	pinned, pin, err := pinnedSource(targetDir, asset, source)
+	if err != nil {
+		return map[string]interface{}{"success": false, "error": err.Error()}
+	}
+	if pinned == "" {
+		// 已安装固定的版本，不会更新
+		return map[string]interface{}{"success": true, "from": pin, "to": pin, "releases": []map[string]interface{}{}}
+	}
+
+	changelog, err := cli.LoadChangelog(releases.CachedForURL, targetDir, asset, pinned)
+	if err != nil {
+		return map[string]interface{}{"success": false, "error": err.Error()}
+	}
+	items := []map[string]interface{}{}
+	for _, release := range changelog.Releases {
+		item := map[string]interface{}{
+			"tag":   release.Tag,
+			"name":  release.Name,
+			"notes": release.Notes,
+		}
+		if !release.PublishedAt.IsZero() {
+			item["date"] = release.PublishedAt.Local().Format("2006-01-02")
+		}
+		items = append(items, item)
+	}
+	result := map[string]interface{}{
+		"success":  true,
+		"from":     changelog.From,
+		"to":       changelog.To,
+		"offline":  changelog.Offline,
+		"releases": items,
+	}
+	if changelog.Offline {
+		result["fetchedAt"] = changelog.FetchedAt.Local().Format("2006-01-02 15:04")
+	}
+	return result
+}
+
+// configTargets 返回配置文件中 targets 与 frontends 指定的目录
+func configTargets(cfg *config.Config) []map[string]interface{} {
+	var result []map[string]interface{}
+	reasons := []string{"配置文件"}
+	for _, dir := range cfg.Targets {
+		dir = system.ExpandHomeDir(dir)
+		result = append(result, map[string]interface{}{
+			"label":    dir,
+			"frontend": string(deploy.FrontendForDir(dir)),
+			"dir":      dir,
+			"reasons":  reasons,
+		})
+	}
+	if len(result) > 0 {
+		return result
+	}
+	for _, name := range cfg.Frontends {
+		frontend, err := deploy.ParseFrontend(name)
+		if err != nil {
+			continue
+		}
+		result = append(result, map[string]interface{}{
+			"label":    name,
+			"frontend": string(frontend),
+			"dir":      deploy.UserDir(frontend),
+			"reasons":  reasons,
+		})
+	}
+	return result
+}
+
+// loadConfig 加载与命令行共用的配置文件，并设置下载代理
+// configHooks 根据配置创建钩子设置
+func configHooks(cfg *config.Config) *updater.Hooks {
+	hooks := updater.DefaultHooks()
+	if cfg.Hooks.Dir != "" {
+		hooks.Dir = system.ExpandHomeDir(cfg.Hooks.Dir)
+	}
+	hooks.RollbackOnPostFailure = cfg.Hooks.Rollback
+	hooks.Commands = cfg.Hooks.Commands.Stages()
+	return hooks
+}
+
+// updateOptions 根据配置创建更新选项，与命令行的 runner.options 对应
+func updateOptions(cfg *config.Config, hooks *updater.Hooks, source string) updater.Options {
+	limits := updater.DefaultExtractLimits
+	cfg.Limits.Apply(&limits)
+	return updater.Options{
+		Source:     source,
+		Hooks:      hooks,
+		NoBackup:   cfg.Backup.Disabled,
+		BackupKeep: cfg.Backup.Keep,
+		Protected:  cfg.Protected,
+		Limits:     &limits,
+		LockWait:   cfg.LockWaitDuration(),
+	}
+}
+
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	if err := downloader.SetProxy(cfg.Proxy); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// GetConfig 返回配置文件路径与生效的配置项（已应用环境变量覆盖）
+func (a *App) GetConfig() map[string]interface{} {
+	result := map[string]interface{}{"path": config.Path()}
+	cfg, err := config.Load()
+	if err != nil {
+		result["error"] = err.Error()
+		return result
+	}
+	values := make(map[string]string)
+	for _, key := range config.Keys() {
+		values[key], _ = cfg.Get(key)
+	}
+	result["values"] = values
+	return result
+}
+
+// OpenConfigFile 用系统默认程序打开配置文件，不存在时先创建示例
+func (a *App) OpenConfigFile() map[string]interface{} {
+	path := config.Path()
+	if err := config.EnsureFile(path); err != nil {
+		return map[string]interface{}{"success": false, "error": err.Error()}
+	}
+	system.OpenFolder(path)
+	return map[string]interface{}{"success": true, "path": path}
+}
+
 func (a *App) GetSystemInfo() map[string]interface{} {
 	return map[string]interface{}{
 		"os": system.DetectOS(),
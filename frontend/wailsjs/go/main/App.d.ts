@@ -1,10 +1,34 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function CheckUpdates(arg1:string):Promise<Record<string, any>>;
+
+export function GetChangelog(arg1:string,arg2:string,arg3:string):Promise<Record<string, any>>;
+
+export function GetConfig():Promise<Record<string, any>>;
+
+export function GetGrammarSchemas(arg1:string):Promise<Record<string, any>>;
+
+export function GetModelVariants():Promise<Array<Record<string, any>>>;
+
+export function GetPendingUpdates():Promise<Array<Record<string, any>>>;
+
 export function GetSystemInfo():Promise<Record<string, any>>;
 
+export function GetTargetCandidates():Promise<Array<Record<string, any>>>;
+
+export function InspectRimeLog(arg1:string,arg2:number):Promise<Record<string, any>>;
+
+export function OpenConfigFile():Promise<Record<string, any>>;
+
 export function OpenUrlBrowser(arg1:string):Promise<Record<string, any>>;
 
+export function Redeploy(arg1:string):Promise<Record<string, any>>;
+
+export function ResolvePendingUpdate(arg1:string,arg2:string):Promise<Record<string, any>>;
+
 export function SelectDirectory():Promise<string>;
 
-export function UpdateAction(arg1:string,arg2:string,arg3:string):Promise<Record<string, any>>;
+export function SetGrammarEnabled(arg1:string,arg2:string,arg3:Array<string>,arg4:boolean):Promise<Record<string, any>>;
+
+export function UpdateAction(arg1:string,arg2:string,arg3:string,arg4:string):Promise<Record<string, any>>;
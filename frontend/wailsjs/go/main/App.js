@@ -2,18 +2,66 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function CheckUpdates(arg1) {
+  return window['go']['main']['App']['CheckUpdates'](arg1);
+}
+
+export function GetChangelog(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetChangelog'](arg1, arg2, arg3);
+}
+
+export function GetConfig() {
+  return window['go']['main']['App']['GetConfig']();
+}
+
+export function GetGrammarSchemas(arg1) {
+  return window['go']['main']['App']['GetGrammarSchemas'](arg1);
+}
+
+export function GetModelVariants() {
+  return window['go']['main']['App']['GetModelVariants']();
+}
+
+export function GetPendingUpdates() {
+  return window['go']['main']['App']['GetPendingUpdates']();
+}
+
 export function GetSystemInfo() {
   return window['go']['main']['App']['GetSystemInfo']();
 }
 
+export function GetTargetCandidates() {
+  return window['go']['main']['App']['GetTargetCandidates']();
+}
+
+export function InspectRimeLog(arg1, arg2) {
+  return window['go']['main']['App']['InspectRimeLog'](arg1, arg2);
+}
+
+export function OpenConfigFile() {
+  return window['go']['main']['App']['OpenConfigFile']();
+}
+
 export function OpenUrlBrowser(arg1) {
   return window['go']['main']['App']['OpenUrlBrowser'](arg1);
 }
 
+export function Redeploy(arg1) {
+  return window['go']['main']['App']['Redeploy'](arg1);
+}
+
+export function ResolvePendingUpdate(arg1, arg2) {
+  return window['go']['main']['App']['ResolvePendingUpdate'](arg1, arg2);
+}
+
 export function SelectDirectory() {
   return window['go']['main']['App']['SelectDirectory']();
 }
 
-export function UpdateAction(arg1, arg2, arg3) {
-  return window['go']['main']['App']['UpdateAction'](arg1, arg2, arg3);
+export function SetGrammarEnabled(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['SetGrammarEnabled'](arg1, arg2, arg3, arg4);
+}
+
+export function UpdateAction(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['UpdateAction'](arg1, arg2, arg3, arg4);
 }
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"oh-my-rime-cli/internal/downloader"
+	"oh-my-rime-cli/internal/releases"
+	"oh-my-rime-cli/internal/updater"
+)
+
+// Changelog 目标目录中的资源从已安装版本更新到新版本所经过的发布
+type Changelog struct {
+	TargetDir string `json:"targetDir"`
+	// From 已安装的版本，未记录时为空
+	From string `json:"from,omitempty"`
+	To   string `json:"to"`
+	// Releases 从新到旧排列，含发布说明
+	Releases []releases.Release `json:"releases"`
+	// Offline 无法连接发布源，使用了 FetchedAt 时缓存的发布说明
+	Offline   bool      `json:"offline,omitempty"`
+	FetchedAt time.Time `json:"fetchedAt,omitempty"`
+}
+
+// LoadChangelog 读取 targetDir 中 asset 的版本记录，返回更新到 downloadURL 对应版本所经过的发布。
+// downloadURL 为 latest 地址时更新到最新的发布；open 通常为 releases.CachedForURL
+func LoadChangelog(open func(url string) (releases.Source, string, error), targetDir, asset, downloadURL string) (*Changelog, error) {
+	installed, err := updater.InstalledVersions(targetDir)
+	if err != nil {
+		return nil, fmt.Errorf("读取版本记录失败: %v", err)
+	}
+	source, _, err := open(downloadURL)
+	if err != nil {
+		return nil, err
+	}
+	list, err := source.Releases()
+	if err != nil {
+		return nil, err
+	}
+
+	changelog := &Changelog{TargetDir: targetDir, From: installed[asset].Version, To: downloader.ReleaseTag(downloadURL)}
+	if cached, ok := source.(*releases.Cached); ok {
+		changelog.Offline, changelog.FetchedAt = cached.Offline, cached.FetchedAt
+	}
+	if (changelog.To == "" || changelog.To == "latest") && len(list) > 0 {
+		changelog.To = list[0].Tag
+	}
+	if changelog.From != changelog.To {
+		changelog.Releases = releases.Between(list, changelog.From, changelog.To)
+	}
+	return changelog, nil
+}
+
+// Print 输出各发布的说明
+func (c *Changelog) Print() {
+	from := c.From
+	if from == "" {
+		from = "未记录的版本"
+	}
+	fmt.Printf("\n📝 更新内容（%s → %s）：\n", from, c.To)
+	if c.Offline {
+		fmt.Printf("（无法连接发布源，以下为 %s 缓存的发布说明）\n", c.FetchedAt.Local().Format("2006-01-02 15:04"))
+	}
+	for _, release := range c.Releases {
+		title := release.Tag
+		if release.Name != "" && release.Name != release.Tag {
+			title += " " + release.Name
+		}
+		if !release.PublishedAt.IsZero() {
+			title += "（" + release.PublishedAt.Local().Format("2006-01-02") + "）"
+		}
+		fmt.Println("\n## " + title)
+		if notes := strings.TrimSpace(release.Notes); notes != "" {
+			fmt.Println(notes)
+		} else {
+			fmt.Println("（没有发布说明）")
+		}
+	}
+	fmt.Println()
+}
+
+// showChangelog 输出各目录将要更新的版本的发布说明，相同的版本范围只输出一次，返回是否有输出。
+// 无法列出发布的地址（如自定义地址）不输出
+func (b *batch) showChangelog() bool {
+	if b.asset == "" {
+		return false
+	}
+	shown := make(map[string]bool)
+	b.r.releaseList = nil
+	for _, i := range b.pending() {
+		changelog, err := LoadChangelog(b.r.releaseSource, b.targets[i].Dir, b.asset, b.sources[i])
+		if err != nil {
+			fmt.Printf("获取发布说明失败: %v\n", err)
+			continue
+		}
+		key := changelog.From + "→" + changelog.To
+		if len(changelog.Releases) == 0 || shown[key] {
+			continue
+		}
+		shown[key] = true
+		changelog.Print()
+		b.r.releaseList = append(b.r.releaseList, changelog.Releases...)
+	}
+	return len(shown) > 0
+}
+
+// confirmChangelog 更新前输出发布说明并询问是否继续（默认继续），取消时返回 false
+func (b *batch) confirmChangelog() bool {
+	if !b.showChangelog() || b.r.prompter() == nil {
+		return true
+	}
+	fmt.Print("是否继续更新？(Y/n)：")
+	input, _ := b.r.reader.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "n", "no":
+		for _, i := range b.pending() {
+			b.errs[i] = fmt.Errorf("已取消更新")
+		}
+		return false
+	}
+	return true
+}
+
+// runChangelog 输出各目标目录更新到最新（或固定、--tag 指定）版本所经过的发布说明，
+// 无法连接发布源时使用缓存
+func (r *runner) runChangelog(asset releaseAsset) error {
+	targets, err := r.targets()
+	if err != nil {
+		return err
+	}
+	b := r.newBatchFor(targets, "", asset.Asset, asset.URL)
+	b.selectVersions()
+	if !b.showChangelog() && len(b.pending()) > 0 {
+		fmt.Printf("%s已是最新版本，或无法获取发布说明\n", asset.Name)
+	}
+	return errors.Join(b.errs...)
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"oh-my-rime-cli/internal/config"
+	"oh-my-rime-cli/internal/releases"
+	"oh-my-rime-cli/internal/updater"
+)
+
+// changelogRunner 返回使用固定发布列表的 runner，目标目录中已安装 v1
+func changelogRunner(t *testing.T, input string) *runner {
+	t.Helper()
+	targetDir := t.TempDir()
+	data, _ := json.Marshal(map[string]updater.AssetVersion{updater.OperationMainScheme: {Version: "v1"}})
+	if err := os.MkdirAll(filepath.Join(targetDir, ".oh-my-rime"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(targetDir, ".oh-my-rime", "versions.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := newRunner(Flags{Targets: listFlag{targetDir}})
+	r.reader = bufio.NewReader(strings.NewReader(input))
+	r.config = &config.Config{}
+	r.releaseSource = func(url string) (releases.Source, string, error) {
+		return releases.Static{
+			{Tag: "v3", Notes: "新增双拼方案"},
+			{Tag: "v2", Notes: "调整快捷键"},
+			{Tag: "v1", Notes: "首个版本"},
+		}, "oh-my-rime.zip", nil
+	}
+	return r
+}
+
+func TestRunChangelogShowsReleasesSinceInstalled(t *testing.T) {
+	r := changelogRunner(t, "")
+	if err := r.runReleaseCommand("changelog", []string{"scheme"}); err != nil {
+		t.Fatalf("changelog returned error: %v", err)
+	}
+	var tags []string
+	for _, release := range r.releaseList {
+		tags = append(tags, release.Tag)
+	}
+	if strings.Join(tags, ",") != "v3,v2" {
+		t.Fatalf("changelog releases = %v; want v3,v2", tags)
+	}
+
+	r.flags.Tag = "v2"
+	if err := r.runReleaseCommand("changelog", []string{"scheme"}); err != nil {
+		t.Fatalf("changelog returned error: %v", err)
+	}
+	if len(r.releaseList) != 1 || r.releaseList[0].Tag != "v2" {
+		t.Fatalf("changelog releases with --tag v2 = %v", r.releaseList)
+	}
+}
+
+func TestUpdateCancelledAfterChangelog(t *testing.T) {
+	r := changelogRunner(t, "n\n")
+	targets, err := r.targets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := r.newBatchFor(targets, updater.OperationMainScheme, updater.OperationMainScheme, r.config.SchemeURL())
+	if b.beforeDownload() {
+		t.Fatal("beforeDownload continued after the update was cancelled")
+	}
+	if err := b.finish(); err == nil || !strings.Contains(err.Error(), "取消") {
+		t.Fatalf("finish error = %v; want cancelled", err)
+	}
+}
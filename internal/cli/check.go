@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"oh-my-rime-cli/internal/config"
+	"oh-my-rime-cli/internal/downloader"
+	"oh-my-rime-cli/internal/updater"
+)
+
+// AssetCheck 一项已安装资源与最新发布的比较结果
+type AssetCheck struct {
+	TargetDir string `json:"targetDir"`
+	// Asset 版本记录中的资源名，见 updater.InstalledVersions
+	Asset  string               `json:"asset"`
+	Name   string               `json:"name"`
+	Status updater.UpdateStatus `json:"status"`
+	// Installed、Latest 已安装与最新的版本：发布版本号，没有时为 ETag 或哈希的前几位
+	Installed   string    `json:"installed,omitempty"`
+	Latest      string    `json:"latest,omitempty"`
+	ReleaseDate time.Time `json:"releaseDate,omitempty"`
+	Size        int64     `json:"size,omitempty"`
+	// Pinned 目标目录固定的版本，update 不会安装其他版本
+	Pinned string `json:"pinned,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// UpdateChecker 查询发布源并与目标目录中的版本记录比较，Probe 可替换以便测试
+type UpdateChecker struct {
+	Config *config.Config
+	Probe  func(url string) (*downloader.RemoteInfo, error)
+
+	// 同一地址只查询一次
+	cache map[string]probeResult
+}
+
+type probeResult struct {
+	info *downloader.RemoteInfo
+	err  error
+}
+
+// NewUpdateChecker 返回使用 cfg 中下载地址的 UpdateChecker
+func NewUpdateChecker(cfg *config.Config) *UpdateChecker {
+	return &UpdateChecker{Config: cfg, Probe: downloader.Probe}
+}
+
+// Check 检查目标目录中的薄荷方案、词库与万象模型是否有新版本。
+// 方案总会检查，词库与模型只检查已安装的
+func (c *UpdateChecker) Check(targetDir string) ([]AssetCheck, error) {
+	installed, err := updater.InstalledVersions(targetDir)
+	if err != nil {
+		return nil, fmt.Errorf("读取版本记录失败: %v", err)
+	}
+	pins, err := updater.Pins(targetDir)
+	if err != nil {
+		return nil, fmt.Errorf("读取固定版本失败: %v", err)
+	}
+
+	checks := []AssetCheck{c.check(targetDir, updater.OperationMainScheme, "薄荷方案", c.Config.SchemeURL(), installed)}
+	if _, ok := installed[updater.OperationDict]; ok {
+		checks = append(checks, c.check(targetDir, updater.OperationDict, "万象词库", c.Config.SchemeURL(), installed))
+	}
+	for _, model := range c.Config.Models() {
+		asset := updater.ModelAsset(model.FileName)
+		_, recorded := installed[asset]
+		if _, err := os.Stat(filepath.Join(targetDir, model.FileName)); recorded || err == nil {
+			checks = append(checks, c.check(targetDir, asset, model.Name, model.URL, installed))
+		}
+	}
+	for i := range checks {
+		checks[i].Pinned = pins[checks[i].Asset]
+	}
+	return checks, nil
+}
+
+func (c *UpdateChecker) check(targetDir, asset, name, url string, installed map[string]updater.AssetVersion) AssetCheck {
+	result := AssetCheck{TargetDir: targetDir, Asset: asset, Name: name}
+	var current *updater.AssetVersion
+	if version, ok := installed[asset]; ok {
+		current = &version
+		result.Installed = versionLabel(version.Version, version.ETag, version.SHA256)
+	}
+
+	remote, err := c.probe(url)
+	if err != nil {
+		result.Status = updater.StatusUnknown
+		result.Error = err.Error()
+		return result
+	}
+	result.Status = updater.CompareVersion(current, remote.Version, remote.ETag)
+	result.Latest = versionLabel(remote.Version, remote.ETag, "")
+	result.ReleaseDate = remote.LastModified
+	result.Size = remote.Size
+	return result
+}
+
+func (c *UpdateChecker) probe(url string) (*downloader.RemoteInfo, error) {
+	if c.cache == nil {
+		c.cache = make(map[string]probeResult)
+	}
+	if cached, ok := c.cache[url]; ok {
+		return cached.info, cached.err
+	}
+	info, err := c.Probe(url)
+	c.cache[url] = probeResult{info: info, err: err}
+	return info, err
+}
+
+// versionLabel 用于显示的版本：优先使用发布版本号，其次是 ETag 或哈希的前 12 位
+func versionLabel(version, etag, sha256 string) string {
+	label := version
+	if label == "" {
+		label = strings.TrimPrefix(strings.Trim(etag, `"`), "W/")
+	}
+	if label == "" {
+		label = sha256
+	}
+	if version == "" && len(label) > 12 {
+		label = label[:12]
+	}
+	return label
+}
+
+// Summary 返回检查结果的一行说明
+func (a AssetCheck) Summary() string {
+	var details []string
+	if !a.ReleaseDate.IsZero() {
+		details = append(details, "发布于 "+a.ReleaseDate.Local().Format("2006-01-02"))
+	}
+	if a.Size > 0 {
+		details = append(details, "大小 "+downloader.FormatBytes(a.Size))
+	}
+	if a.Pinned != "" {
+		details = append(details, "已固定在 "+a.Pinned)
+	}
+	detail := ""
+	if len(details) > 0 {
+		detail = "，" + strings.Join(details, "，")
+	}
+
+	switch {
+	case a.Error != "":
+		return fmt.Sprintf("⚠️  %s: 查询失败: %s", a.Name, a.Error)
+	case a.Status == updater.StatusUpToDate:
+		return fmt.Sprintf("✅ %s: 已是最新（%s%s）", a.Name, a.Installed, detail)
+	case a.Status == updater.StatusUpdateAvailable:
+		return fmt.Sprintf("⬆️  %s: 有新版本 %s 可用（当前 %s%s）", a.Name, a.Latest, a.Installed, detail)
+	case a.Status == updater.StatusNotInstalled:
+		return fmt.Sprintf("➖ %s: 未记录安装版本，最新版本 %s%s", a.Name, a.Latest, detail)
+	}
+	return fmt.Sprintf("❔ %s: 无法与最新版本比较（当前 %s），最新版本 %s%s", a.Name, a.Installed, a.Latest, detail)
+}
+
+// runCheck 检查各目标目录是否有新版本，任一资源查询失败时返回下载错误
+func (r *runner) runCheck() error {
+	targets, err := r.targets()
+	if err != nil {
+		return err
+	}
+	checker := NewUpdateChecker(r.config)
+	var failed int
+	r.checks = []AssetCheck{}
+	for _, t := range targets {
+		fmt.Println("\n目标目录: ", t.Dir)
+		checks, err := checker.Check(t.Dir)
+		if err != nil {
+			return err
+		}
+		for _, check := range checks {
+			fmt.Println("  " + check.Summary())
+			if check.Error != "" {
+				failed++
+			}
+		}
+		r.checks = append(r.checks, checks...)
+	}
+	if failed > 0 {
+		return downloadError(fmt.Sprintf("%d 项资源查询失败，请检查网络连接或稍后重试", failed))
+	}
+	return nil
+}
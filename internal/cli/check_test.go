@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"oh-my-rime-cli/internal/updater"
+)
+
+// releaseServer 模拟发布源：latest 重定向到当前版本，也可下载任意旧版本，支持 Range 与 ETag
+type releaseServer struct {
+	*httptest.Server
+	version string
+	etag    string
+	zip     []byte
+}
+
+func newReleaseServer(t *testing.T) *releaseServer {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, _ := zw.Create("default.yaml")
+	w.Write([]byte("new"))
+	zw.Close()
+
+	s := &releaseServer{version: "v1", etag: `"one"`, zip: buf.Bytes()}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version, ok := strings.CutPrefix(r.URL.Path, "/releases/download/")
+		version, ok = strings.CutSuffix(version, "/oh-my-rime.zip")
+		switch {
+		case !ok:
+			http.NotFound(w, r)
+		case version == "latest":
+			http.Redirect(w, r, "/releases/download/"+s.version+"/oh-my-rime.zip", http.StatusFound)
+		default:
+			// 旧版本使用以版本号为内容的 ETag
+			etag := `"` + version + `"`
+			if version == s.version {
+				etag = s.etag
+			}
+			w.Header().Set("ETag", etag)
+			w.Header().Set("Content-Type", "application/zip")
+			http.ServeContent(w, r, "oh-my-rime.zip", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), bytes.NewReader(s.zip))
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestRunCheckReportsNewVersion(t *testing.T) {
+	t.Setenv("OH_MY_RIME_CONFIG_DIR", t.TempDir())
+	server := newReleaseServer(t)
+	t.Setenv("OH_MY_RIME_SOURCES_SCHEME", server.URL+"/releases/download/latest/oh-my-rime.zip")
+	targetDir := t.TempDir()
+
+	checkStatus := func() (int, map[string]interface{}) {
+		t.Helper()
+		code, events := runJSON(t, "check", "--target", targetDir)
+		checks, _ := events[len(events)-1]["check"].([]interface{})
+		if len(checks) != 1 {
+			t.Fatalf("check = %v; want one asset", events[len(events)-1])
+		}
+		return code, checks[0].(map[string]interface{})
+	}
+
+	if _, check := checkStatus(); check["status"] != string(updater.StatusNotInstalled) || check["latest"] != "v1" {
+		t.Fatalf("before update: %v", check)
+	}
+
+	if code := Run([]string{"update", "scheme", "--target", targetDir, "--yes", "--no-backup"}); code != ExitOK {
+		t.Fatalf("update scheme = %d; want %d", code, ExitOK)
+	}
+	if code, check := checkStatus(); code != ExitOK || check["status"] != string(updater.StatusUpToDate) || check["installed"] != "v1" {
+		t.Fatalf("after update: %d %v", code, check)
+	}
+
+	server.version, server.etag = "v2", `"two"`
+	_, check := checkStatus()
+	if check["status"] != string(updater.StatusUpdateAvailable) || check["latest"] != "v2" || check["size"] != float64(len(server.zip)) {
+		t.Fatalf("after new release: %v", check)
+	}
+}
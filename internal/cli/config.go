@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"oh-my-rime-cli/internal/config"
+	"oh-my-rime-cli/internal/deploy"
+	"oh-my-rime-cli/internal/system"
+)
+
+// runConfig 执行 config 子命令：path 显示配置文件路径，get 查看生效的配置，set 修改配置文件，edit 用编辑器打开
+func (r *runner) runConfig(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: config 需要指定 path、get、set 或 edit", errUsage)
+	}
+	path := config.Path()
+	switch action, rest := args[0], args[1:]; {
+	case action == "path" && len(rest) == 0:
+		fmt.Println(path)
+		return nil
+	case action == "get" && len(rest) <= 1:
+		return r.configGet(rest)
+	case action == "set" && len(rest) == 2:
+		return configSet(path, rest[0], rest[1])
+	case action == "edit" && len(rest) == 0:
+		return configEdit(path)
+	case action == "path" || action == "get" || action == "set" || action == "edit":
+		return fmt.Errorf("%w: 用法 config path|get [项]|set <项> <值>|edit", errUsage)
+	default:
+		return fmt.Errorf("%w: 未知的 config 操作 %s（可选 path、get、set、edit）", errUsage, action)
+	}
+}
+
+// configGet 输出已应用环境变量覆盖的配置项
+func (r *runner) configGet(keys []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		keys = config.Keys()
+	}
+	r.configValues = make(map[string]string, len(keys))
+	for _, key := range keys {
+		value, err := cfg.Get(key)
+		if err != nil {
+			return fmt.Errorf("%w: %v", errUsage, err)
+		}
+		r.configValues[key] = value
+		if len(keys) == 1 {
+			fmt.Println(value)
+		} else {
+			fmt.Printf("%s = %s\n", key, value)
+		}
+	}
+	return nil
+}
+
+// configSet 修改配置文件中的一项，值为空时清除该项
+func configSet(path, key, value string) error {
+	cfg, err := config.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := cfg.Set(key, value); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	for _, frontend := range cfg.Frontends {
+		if _, err := deploy.ParseFrontend(frontend); err != nil {
+			return fmt.Errorf("%w: %v", errUsage, err)
+		}
+	}
+	if err := cfg.Save(path); err != nil {
+		return err
+	}
+
+	saved, _ := cfg.Get(key)
+	fmt.Printf("已设置 %s = %s\n", key, saved)
+	if env := config.EnvName(key); os.Getenv(env) != "" {
+		fmt.Printf("⚠️  环境变量 %s 已设置，会覆盖配置文件中的值\n", env)
+	}
+	return nil
+}
+
+// configEdit 用编辑器打开配置文件，文件不存在时先写入示例。编辑后检查文件是否有效
+func configEdit(path string) error {
+	if err := config.EnsureFile(path); err != nil {
+		return err
+	}
+
+	editor := editorCommand()
+	cmd := exec.Command(editor[0], append(editor[1:], path)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("运行编辑器 %s 失败: %v（可设置 EDITOR 环境变量）", editor[0], err)
+	}
+
+	if _, err := config.ReadFile(path); err != nil {
+		return fmt.Errorf("%v，请重新运行 config edit 修改", err)
+	}
+	fmt.Println("配置文件已保存: ", path)
+	return nil
+}
+
+// editorCommand 返回编辑器命令：VISUAL、EDITOR 环境变量优先，否则使用系统默认的文本编辑器
+func editorCommand() []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.Fields(os.Getenv(env)); len(editor) > 0 {
+			return editor
+		}
+	}
+	switch system.DetectOS() {
+	case "Windows_NT":
+		return []string{"notepad"}
+	case "Darwin":
+		// -W 等待编辑器关闭后再检查文件
+		return []string{"open", "-W", "-t"}
+	}
+	return []string{"vi"}
+}
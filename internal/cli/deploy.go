@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strings"
+
+	"oh-my-rime-cli/internal/deploy"
+)
+
+// Redeploy 更新完成后重新部署前端。auto 为 false 时先询问用户，reader 为 nil 时不询问也不部署，返回是否已部署
+func Redeploy(reader *bufio.Reader, frontend deploy.Frontend, auto bool) bool {
+	if !auto && reader == nil {
+		return false
+	}
+	if frontend == deploy.FrontendUnknown {
+		fmt.Println("无法识别该目录所属的输入法前端，请手动重新部署 Rime")
+		return false
+	}
+	if !auto && !confirm(reader, fmt.Sprintf("是否立即重新部署 Rime（%s）？(y/N)：", frontend)) {
+		return false
+	}
+
+	command, err := deploy.Deploy(frontend)
+	if err != nil {
+		if errors.Is(err, deploy.ErrNoDeployCommand) {
+			fmt.Printf("%v，请手动重新部署 Rime\n", err)
+		} else {
+			fmt.Printf("重新部署失败: %v\n", err)
+		}
+		return false
+	}
+	fmt.Printf("✅ 已重新部署 Rime: %s\n", command)
+	return true
+}
+
+func confirm(reader *bufio.Reader, prompt string) bool {
+	fmt.Print(prompt)
+	input, _ := reader.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
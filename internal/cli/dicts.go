@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"oh-my-rime-cli/internal/downloader"
+	"oh-my-rime-cli/internal/updater"
+)
+
+// SplitGlobs 解析逗号分隔的匹配规则
+func SplitGlobs(value string) []string {
+	var globs []string
+	for _, glob := range strings.Split(value, ",") {
+		if glob = strings.TrimSpace(glob); glob != "" {
+			globs = append(globs, glob)
+		}
+	}
+	return globs
+}
+
+// ChooseDicts 决定本次安装的词库：指定了 include/exclude 规则时直接使用，
+// 否则按 nameEncoding 解码文件名后列出词库供用户选择（reader 为 nil 时不询问）。返回 nil 表示沿用目标目录保存的选择
+func ChooseDicts(reader *bufio.Reader, rimeZip []byte, targetDir, include, exclude, nameEncoding string) (*updater.DictSelection, error) {
+	if include != "" || exclude != "" {
+		selection := &updater.DictSelection{Include: SplitGlobs(include), Exclude: SplitGlobs(exclude)}
+		if err := updater.ValidateGlobs(append(selection.Include, selection.Exclude...)); err != nil {
+			return nil, err
+		}
+		return selection, nil
+	}
+	if reader == nil {
+		return nil, nil
+	}
+
+	dicts, err := updater.ListDicts(rimeZip, nameEncoding)
+	if err != nil {
+		return nil, err
+	}
+	current, err := updater.LoadDictSelection(targetDir)
+	if err != nil {
+		fmt.Printf("读取已保存的词库选择失败: %v\n", err)
+	}
+	return promptDicts(reader, dicts, current), nil
+}
+
+func promptDicts(reader *bufio.Reader, dicts []updater.DictInfo, current *updater.DictSelection) *updater.DictSelection {
+	if len(dicts) == 0 {
+		return nil
+	}
+
+	fmt.Println("\n==============================")
+	fmt.Println(" 可安装的词库 ")
+	fmt.Println("==============================")
+	for i, dict := range dicts {
+		mark := " "
+		if current.Matches(dict.Path) {
+			mark = "x"
+		}
+		entries := "-"
+		if dict.Entries > 0 {
+			entries = strconv.Itoa(dict.Entries) + " 条"
+		}
+		fmt.Printf("[%s] %2d. %-40s %10s %12s\n", mark, i+1, dict.Path, downloader.FormatBytes(dict.Size), entries)
+	}
+	fmt.Println("------------------------------")
+	fmt.Println("直接回车: 沿用上次的选择（首次为全部安装）")
+	fmt.Println("a: 全部安装；输入编号选择，例如 1,3,5-7")
+	fmt.Print("请选择要安装的词库：")
+
+	for {
+		input, _ := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+		switch input {
+		case "":
+			return nil
+		case "a", "A":
+			return &updater.DictSelection{}
+		}
+
+		chosen, err := parseIndexes(input, len(dicts))
+		if err != nil {
+			fmt.Printf("%v，请重新输入：", err)
+			continue
+		}
+
+		// 保存未选择的词库为排除规则，上游新增的词库默认安装
+		selection := &updater.DictSelection{}
+		for i, dict := range dicts {
+			if !chosen[i] {
+				selection.Exclude = append(selection.Exclude, dict.Path)
+			}
+		}
+		return selection
+	}
+}
+
+// parseIndexes 解析 "1,3,5-7" 形式的编号（从 1 开始），返回从 0 开始的下标集合
+func parseIndexes(input string, count int) (map[int]bool, error) {
+	chosen := make(map[int]bool)
+	for _, part := range strings.FieldsFunc(input, func(r rune) bool { return r == ',' || r == ' ' || r == '，' }) {
+		start, end := part, part
+		if i := strings.Index(part, "-"); i > 0 {
+			start, end = part[:i], part[i+1:]
+		}
+		from, err1 := strconv.Atoi(start)
+		to, err2 := strconv.Atoi(end)
+		if err1 != nil || err2 != nil || from < 1 || to > count || from > to {
+			return nil, fmt.Errorf("无效编号: %s", part)
+		}
+		for i := from; i <= to; i++ {
+			chosen[i-1] = true
+		}
+	}
+	if len(chosen) == 0 {
+		return nil, fmt.Errorf("未选择任何词库")
+	}
+	return chosen, nil
+}
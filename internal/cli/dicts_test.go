@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"bufio"
+	"reflect"
+	"strings"
+	"testing"
+
+	"oh-my-rime-cli/internal/updater"
+)
+
+func TestPromptDicts(t *testing.T) {
+	dicts := []updater.DictInfo{
+		{Path: "base.dict.yaml"},
+		{Path: "ext.dict.yaml"},
+		{Path: "tencent.dict.yaml"},
+		{Path: "emoji.dict.yaml"},
+	}
+
+	tests := []struct {
+		input string
+		want  *updater.DictSelection
+	}{
+		{input: "\n", want: nil},
+		{input: "a\n", want: &updater.DictSelection{}},
+		{input: "1,4\n", want: &updater.DictSelection{Exclude: []string{"ext.dict.yaml", "tencent.dict.yaml"}}},
+		{input: "1-3\n", want: &updater.DictSelection{Exclude: []string{"emoji.dict.yaml"}}},
+		{input: "9\n2 3，4\n", want: &updater.DictSelection{Exclude: []string{"base.dict.yaml"}}},
+	}
+
+	for _, tt := range tests {
+		got := promptDicts(bufio.NewReader(strings.NewReader(tt.input)), dicts, nil)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("promptDicts(%q) = %+v; want %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestChooseDictsFromGlobs(t *testing.T) {
+	selection, err := ChooseDicts(nil, nil, "", "base*, emoji*", "*.ext.dict.yaml", updater.NameEncodingAuto)
+	if err != nil {
+		t.Fatalf("ChooseDicts returned error: %v", err)
+	}
+	want := &updater.DictSelection{Include: []string{"base*", "emoji*"}, Exclude: []string{"*.ext.dict.yaml"}}
+	if !reflect.DeepEqual(selection, want) {
+		t.Fatalf("ChooseDicts = %+v; want %+v", selection, want)
+	}
+
+	if _, err := ChooseDicts(nil, nil, "", "[", "", updater.NameEncodingAuto); err == nil {
+		t.Fatal("ChooseDicts with invalid glob returned nil; want error")
+	}
+}
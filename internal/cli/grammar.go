@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"oh-my-rime-cli/internal/updater"
+)
+
+// ConfigureGrammar 模型更新后在方案的 custom 文件中启用语法模型。
+// schemas 为逗号分隔的方案 ID，"all" 表示全部方案，"none" 表示跳过，"revert" 表示撤销之前的修改，
+// 为空时交互询问（reader 为 nil 时跳过）
+func ConfigureGrammar(reader *bufio.Reader, targetDir, modelName, schemas string) {
+	if strings.TrimSpace(schemas) == "" && reader == nil {
+		return
+	}
+
+	available, err := updater.ListSchemas(targetDir)
+	if err != nil {
+		fmt.Printf("读取方案列表失败: %v\n", err)
+		return
+	}
+	patched, err := updater.GrammarPatchedSchemas(targetDir)
+	if err != nil {
+		fmt.Printf("读取语法模型修改记录失败: %v\n", err)
+	}
+
+	var chosen []string
+	revert := false
+	switch strings.TrimSpace(schemas) {
+	case "":
+		chosen, revert = promptGrammarSchemas(reader, modelName, available, patched)
+	case "none":
+	case "all":
+		chosen = available
+	case "revert":
+		revert = true
+	default:
+		chosen = SplitGlobs(schemas)
+	}
+
+	if revert {
+		if err := updater.DisableGrammar(targetDir); err != nil {
+			fmt.Printf("撤销语法模型设置失败: %v\n", err)
+		}
+		return
+	}
+	if len(chosen) == 0 {
+		return
+	}
+	if err := updater.EnableGrammar(targetDir, modelName, chosen); err != nil {
+		fmt.Printf("启用语法模型失败: %v\n", err)
+		return
+	}
+	fmt.Println("请重新部署 Rime 使语法模型生效")
+}
+
+func promptGrammarSchemas(reader *bufio.Reader, modelName string, available, patched []string) ([]string, bool) {
+	if len(available) == 0 && len(patched) == 0 {
+		return nil, false
+	}
+
+	fmt.Println("\n==============================")
+	fmt.Printf(" 在方案中启用语法模型 %s \n", modelName)
+	fmt.Println("==============================")
+	if len(patched) > 0 {
+		fmt.Printf("已启用的方案: %s\n", strings.Join(patched, ", "))
+	}
+	for i, schema := range available {
+		fmt.Printf("%d. %s\n", i+1, schema)
+	}
+	fmt.Println("------------------------------")
+	fmt.Println("直接回车: 跳过；a: 全部方案；输入编号选择，例如 1,3")
+	if len(patched) > 0 {
+		fmt.Println("r: 撤销之前对 custom 文件的修改")
+	}
+	fmt.Print("请选择：")
+
+	for {
+		input, _ := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+		switch input {
+		case "":
+			return nil, false
+		case "a", "A":
+			return available, false
+		case "r", "R":
+			if len(patched) > 0 {
+				return nil, true
+			}
+		}
+
+		indexes, err := parseIndexes(input, len(available))
+		if err != nil {
+			fmt.Print("无效的选择，请重新输入：")
+			continue
+		}
+		var chosen []string
+		for i, schema := range available {
+			if indexes[i] {
+				chosen = append(chosen, schema)
+			}
+		}
+		return chosen, false
+	}
+}
@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"bufio"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPromptGrammarSchemas(t *testing.T) {
+	available := []string{"double_pinyin", "rime_mint", "terra_pinyin"}
+
+	tests := []struct {
+		input      string
+		patched    []string
+		want       []string
+		wantRevert bool
+	}{
+		{input: "\n", want: nil},
+		{input: "a\n", want: available},
+		{input: "1,3\n", want: []string{"double_pinyin", "terra_pinyin"}},
+		{input: "r\n2\n", want: []string{"rime_mint"}},
+		{input: "r\n", patched: []string{"rime_mint"}, wantRevert: true},
+	}
+	for _, tt := range tests {
+		got, revert := promptGrammarSchemas(bufio.NewReader(strings.NewReader(tt.input)), "model.gram", available, tt.patched)
+		if !reflect.DeepEqual(got, tt.want) || revert != tt.wantRevert {
+			t.Errorf("promptGrammarSchemas(%q) = %v, %v; want %v, %v", tt.input, got, revert, tt.want, tt.wantRevert)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package cli
+
+import (
+	"fmt"
+
+	"oh-my-rime-cli/internal/updater"
+)
+
+// BeforeDownload 执行 pre-download 钩子，返回错误表示应取消本次更新
+func BeforeDownload(hooks *updater.Hooks, operation, targetDir, source string) error {
+	if err := updater.RunPreDownloadHooks(hooks, operation, targetDir, source); err != nil {
+		return fmt.Errorf("%w，已取消更新", err)
+	}
+	return nil
+}
+
+// DownloadFailed 执行 post-failure 钩子，返回以 message 为内容的下载错误
+func DownloadFailed(hooks *updater.Hooks, operation, targetDir, source, message string) error {
+	err := downloadError(message)
+	updater.RunFailureHooks(hooks, operation, targetDir, source, err)
+	return err
+}
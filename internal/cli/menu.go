@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"oh-my-rime-cli/internal/constants"
+	"oh-my-rime-cli/internal/deploy"
+	"oh-my-rime-cli/internal/system"
+)
+
+// runMenu 交互菜单，未指定子命令时使用
+func (r *runner) runMenu() error {
+	fmt.Println("欢迎使用: ", constants.AppName)
+	fmt.Println("工具版本: ", constants.AppVersion)
+
+	// 检测操作系统
+	currentOS := system.DetectOS()
+	if currentOS == "Unknown" {
+		return fmt.Errorf("无法识别当前操作系统，请确保在支持的操作系统上运行")
+	}
+	fmt.Printf("当前操作系统: %s\n", currentOS)
+
+	// 检查上次是否有被中断的更新
+	if r.flags.Yes {
+		r.warnPendingUpdates()
+	} else {
+		RecoverInterruptedUpdates(r.reader, r.options)
+	}
+
+	for {
+		showMenu()
+
+		input, err := r.reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+		if input == "" && err != nil {
+			// 标准输入已关闭
+			return nil
+		}
+
+		if !r.handleUserChoice(input) {
+			return nil
+		}
+	}
+}
+
+// 显示主菜单
+func showMenu() {
+	fmt.Println("\n", strings.Repeat("=", 60))
+	fmt.Println(" 作者: ", constants.APPAuthor)
+	fmt.Println(" 开源地址: ", constants.APPOpenSource)
+	fmt.Println("\n", strings.Repeat("=", 60))
+	fmt.Println("工作原理：")
+	fmt.Println("  • 下载最新的方案或模型文件")
+	fmt.Println("  • 替换当前 Rime 配置目录下的同名文件")
+	fmt.Println("")
+	fmt.Println("功能选项：")
+	fmt.Println("  [1] 更新薄荷方案              [2] 更新万象模型")
+	fmt.Println("  [3] 更新万象词库（Lite版）     [4] 自定义更新")
+	fmt.Println("")
+	fmt.Println("其他选项：")
+	fmt.Println("  [b] 打开作者 Bilibili (关注一下 ヾ(≧≦)〃)")
+	fmt.Println("  [d] 打开薄荷输入法文档")
+	fmt.Println("  [l] 检查 Rime 部署日志")
+	fmt.Println("  [q] 退出程序")
+	fmt.Println("")
+	fmt.Println(strings.Repeat("-", 60))
+	fmt.Print("请输入选项 (1/2/3/4/b/d/l/q)：")
+}
+
+// 处理用户选择的操作
+func (r *runner) handleUserChoice(choice string) bool {
+	var err error
+	switch choice {
+	case "1":
+		err = r.updateScheme()
+	case "2":
+		err = r.updateModel()
+	case "3":
+		err = r.updateDict()
+	case "4":
+		err = r.customUpdate()
+	case "b":
+		fmt.Println("打开作者 Bilibili ...")
+		system.OpenUrlBrowser(constants.APPAuthorBilibili)
+	case "d":
+		fmt.Println("打开薄荷输入法文档 ...")
+		system.OpenUrlBrowser(constants.AppURL)
+	case "l":
+		var targets []target
+		if targets, err = r.targets(); err == nil {
+			checked := make(map[deploy.Frontend]bool)
+			for _, t := range targets {
+				if !checked[t.Frontend] {
+					checked[t.Frontend] = true
+					CheckRimeLog(t.Frontend, time.Time{})
+				}
+			}
+		}
+	case "q":
+		fmt.Println("感谢使用！记得更新后，重新部署方案以使更改生效")
+		return false
+	default:
+		fmt.Println("无效选项，请重新输入")
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	return true
+}
+
+// 自定义更新：询问下载地址后更新
+func (r *runner) customUpdate() error {
+	fmt.Println("\n==============================")
+	fmt.Println("自定义更新功能: ")
+	fmt.Println("粘贴方案打包的 zip 文件 URL => 将下载并替换当前 Rime 配置目录下的文件")
+	fmt.Println("粘贴模型的 gram 文件 URL => 将下载并替换当前 Rime 配置目录下的同名文件")
+	fmt.Println("URL 下载失败不会更新任何文件，本质是同名文件覆盖")
+	fmt.Println("==============================")
+	fmt.Println("请粘贴 URL （例如：https://github.com/Mintimate/oh-my-rime/archive/refs/heads/main.zip）：")
+	customURL, _ := r.reader.ReadString('\n')
+	return r.updateCustom(strings.TrimSpace(customURL))
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"oh-my-rime-cli/internal/constants"
+	"oh-my-rime-cli/internal/downloader"
+	"oh-my-rime-cli/internal/updater"
+)
+
+// ModelFileName 决定模型写入的文件名：指定了 override 时优先使用，否则使用下载时得到的文件名
+func ModelFileName(override, suggested string) (string, error) {
+	name := strings.TrimSpace(override)
+	if name == "" {
+		name = suggested
+	}
+	if name == "" {
+		return updater.DefaultModelName, nil
+	}
+	if err := updater.ValidateModelName(name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// ChooseModel 列出目标目录已安装的模型，并让用户从 models 中选择要下载的万象模型版本。reader 为 nil 时使用第一个（默认）版本
+func ChooseModel(reader *bufio.Reader, targetDir string, models []constants.WanXiangModel) constants.WanXiangModel {
+	if reader == nil {
+		return models[0]
+	}
+	installed, err := updater.ListModels(targetDir)
+	if err != nil {
+		fmt.Printf("读取已安装的模型失败: %v\n", err)
+	}
+	return promptModel(reader, models, installed)
+}
+
+func promptModel(reader *bufio.Reader, models []constants.WanXiangModel, installed []updater.ModelInfo) constants.WanXiangModel {
+	if len(models) == 1 {
+		return models[0]
+	}
+
+	if len(installed) > 0 {
+		fmt.Println("\n已安装的模型：")
+		for _, model := range installed {
+			fmt.Printf("  %-36s %10s  %s\n", model.Name, downloader.FormatBytes(model.Size), model.ModTime.Format("2006-01-02 15:04"))
+		}
+	}
+
+	installedNames := make(map[string]bool, len(installed))
+	for _, model := range installed {
+		installedNames[model.Name] = true
+	}
+
+	fmt.Println("\n==============================")
+	fmt.Println(" 可下载的模型 ")
+	fmt.Println("==============================")
+	for i, model := range models {
+		mark := ""
+		if installedNames[model.FileName] {
+			mark = "（已安装，将覆盖）"
+		}
+		fmt.Printf("%d. %s - %s%s\n", i+1, model.Name, model.FileName, mark)
+	}
+	fmt.Print("请选择要下载的模型（直接回车选择 1）：")
+
+	for {
+		input, _ := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+		if input == "" {
+			return models[0]
+		}
+		index, err := strconv.Atoi(input)
+		if err != nil || index < 1 || index > len(models) {
+			fmt.Print("无效的选择，请重新输入：")
+			continue
+		}
+		return models[index-1]
+	}
+}
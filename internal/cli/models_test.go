@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"oh-my-rime-cli/internal/constants"
+	"oh-my-rime-cli/internal/updater"
+)
+
+func TestPromptModel(t *testing.T) {
+	models := []constants.WanXiangModel{
+		{Name: "简体", FileName: "hans.gram"},
+		{Name: "繁体", FileName: "hant.gram"},
+	}
+	installed := []updater.ModelInfo{{Name: "hans.gram", Size: 1}}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "\n", want: "hans.gram"},
+		{input: "2\n", want: "hant.gram"},
+		{input: "3\nx\n2\n", want: "hant.gram"},
+	}
+	for _, tt := range tests {
+		got := promptModel(bufio.NewReader(strings.NewReader(tt.input)), models, installed)
+		if got.FileName != tt.want {
+			t.Errorf("promptModel(%q) = %s; want %s", tt.input, got.FileName, tt.want)
+		}
+	}
+}
+
+func TestModelFileName(t *testing.T) {
+	tests := []struct {
+		override, suggested, want string
+		wantErr                   bool
+	}{
+		{suggested: "wanxiang-lts-zh-hant.gram", want: "wanxiang-lts-zh-hant.gram"},
+		{override: "mine.gram", suggested: "wanxiang-lts-zh-hant.gram", want: "mine.gram"},
+		{want: updater.DefaultModelName},
+		{suggested: "download", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ModelFileName(tt.override, tt.suggested)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ModelFileName(%q, %q) = %q, %v; want %q, error %v", tt.override, tt.suggested, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"oh-my-rime-cli/internal/downloader"
+	"oh-my-rime-cli/internal/releases"
+	"oh-my-rime-cli/internal/updater"
+)
+
+// 输出格式
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+// 进程退出码
+const (
+	ExitOK      = 0
+	ExitFailure = 1
+	// ExitUsage 命令行参数错误
+	ExitUsage = 2
+	// ExitNetwork 下载失败，目标目录未被修改
+	ExitNetwork = 3
+	// ExitValidation 更新包未通过校验（zip 损坏、不安全路径、模型无效、超出解压限制等）
+	ExitValidation = 4
+	// ExitRolledBack 更新失败，已恢复到更新前状态
+	ExitRolledBack = 5
+	// ExitRollbackFailed 更新失败且备份恢复失败，需要手动处理
+	ExitRollbackFailed = 6
+)
+
+// 错误代码，与退出码一一对应，写入 JSON 输出
+const (
+	CodeFailure        = "failure"
+	CodeUsage          = "usage"
+	CodeNetwork        = "network"
+	CodeValidation     = "validation"
+	CodeRolledBack     = "rolled-back"
+	CodeRollbackFailed = "rollback-failed"
+)
+
+// errUsage 命令行用法错误
+var errUsage = errors.New("参数错误")
+
+// errDownload 下载失败（网络错误、HTTP 错误或下载到 HTML 页面）
+var errDownload = errors.New("下载失败")
+
+// downloadError 保留下载失败的提示，可通过 errors.Is 匹配 errDownload
+type downloadError string
+
+func (e downloadError) Error() string        { return string(e) }
+func (e downloadError) Is(target error) bool { return target == errDownload }
+
+// ExitCode 返回错误对应的退出码与错误代码。
+// 优先级：备份恢复失败 > 更新包无效 > 已回滚 > 下载失败
+func ExitCode(err error) (int, string) {
+	switch {
+	case err == nil:
+		return ExitOK, ""
+	case errors.Is(err, errUsage):
+		return ExitUsage, CodeUsage
+	case errors.Is(err, updater.ErrRollbackFailed):
+		return ExitRollbackFailed, CodeRollbackFailed
+	case updater.IsInvalidPackage(err):
+		return ExitValidation, CodeValidation
+	case errors.Is(err, updater.ErrRolledBack):
+		return ExitRolledBack, CodeRolledBack
+	case errors.Is(err, errDownload):
+		return ExitNetwork, CodeNetwork
+	}
+	return ExitFailure, CodeFailure
+}
+
+// ProgressEvent 下载进度事件
+type ProgressEvent struct {
+	Type       string  `json:"type"`
+	Downloaded int64   `json:"downloaded"`
+	Total      int64   `json:"total"`
+	Percentage float64 `json:"percentage"`
+	Speed      float64 `json:"speed"`
+}
+
+// ErrorEvent 命令失败时输出的错误
+type ErrorEvent struct {
+	Type     string `json:"type"`
+	Code     string `json:"code"`
+	ExitCode int    `json:"exitCode"`
+	Message  string `json:"message"`
+}
+
+// TargetEvent 附带目标目录的更新事件，同时更新多个目录时用于区分
+type TargetEvent struct {
+	updater.Event
+	TargetDir string `json:"targetDir"`
+}
+
+// targetResult 单个目标目录的更新结果
+type targetResult struct {
+	TargetDir string      `json:"targetDir"`
+	OK        bool        `json:"ok"`
+	ExitCode  int         `json:"exitCode"`
+	Error     *ErrorEvent `json:"error,omitempty"`
+	// Pinned 已安装固定的版本而跳过更新时为固定的版本
+	Pinned string `json:"pinned,omitempty"`
+}
+
+func newTargetResult(targetDir string, err error) targetResult {
+	code, name := ExitCode(err)
+	result := targetResult{TargetDir: targetDir, OK: err == nil, ExitCode: code}
+	if err != nil {
+		result.Error = &ErrorEvent{Type: "error", Code: name, ExitCode: code, Message: err.Error()}
+	}
+	return result
+}
+
+// Result 命令结束时输出的最终结果。只有一个目标目录时填写 TargetDir，多个时填写 Targets
+type Result struct {
+	Type      string         `json:"type"`
+	OK        bool           `json:"ok"`
+	Command   string         `json:"command"`
+	TargetDir string         `json:"targetDir,omitempty"`
+	Targets   []targetResult `json:"targets,omitempty"`
+	// Config config get 输出的配置项
+	Config map[string]string `json:"config,omitempty"`
+	// Check check 的结果
+	Check []AssetCheck `json:"check,omitempty"`
+	// Releases releases 列出的发布版本，或 changelog、update 显示了说明的发布
+	Releases []releases.Release `json:"releases,omitempty"`
+	ExitCode int                `json:"exitCode"`
+	Error    *ErrorEvent        `json:"error,omitempty"`
+}
+
+// jsonOutput 以每行一个 JSON 对象的形式输出事件
+type jsonOutput struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+func newJSONOutput(w io.Writer) *jsonOutput {
+	return &jsonOutput{encoder: json.NewEncoder(w)}
+}
+
+func (o *jsonOutput) emit(event interface{}) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if err := o.encoder.Encode(event); err != nil {
+		fmt.Fprintf(os.Stderr, "写入 JSON 输出失败: %v\n", err)
+	}
+}
+
+// useJSONOutput 切换为 JSON 输出：事件写入标准输出，其余文字改写到标准错误。返回恢复函数
+func (r *runner) useJSONOutput() func() {
+	stdout := os.Stdout
+	r.out = newJSONOutput(stdout)
+	os.Stdout = os.Stderr
+	return func() { os.Stdout = stdout }
+}
+
+// progress 返回下载进度回调，文本输出时返回 nil（使用终端进度条）
+func (r *runner) progress() downloader.ProgressCallback {
+	if r.out == nil {
+		return nil
+	}
+	return func(downloaded, total int64, percentage float64, speed float64) {
+		r.out.emit(ProgressEvent{Type: "progress", Downloaded: downloaded, Total: total, Percentage: percentage, Speed: speed})
+	}
+}
+
+// events 返回更新 targetDir 时的事件回调，文本输出时返回 nil
+func (r *runner) events(targetDir string) updater.EventCallback {
+	if r.out == nil {
+		return nil
+	}
+	return func(event updater.Event) {
+		r.out.emit(TargetEvent{Event: event, TargetDir: targetDir})
+	}
+}
+
+// finish 输出命令的结果并返回退出码
+func (r *runner) finish(command string, err error) int {
+	code, name := ExitCode(err)
+	if r.out == nil {
+		if err != nil {
+			fmt.Println(err)
+		}
+		return code
+	}
+
+	result := Result{Type: "result", OK: err == nil, Command: command, Config: r.configValues, Check: r.checks, Releases: r.releaseList, ExitCode: code}
+	if len(r.results) == 1 {
+		result.TargetDir = r.results[0].TargetDir
+	} else {
+		result.Targets = r.results
+	}
+	if err != nil {
+		result.Error = &ErrorEvent{Type: "error", Code: name, ExitCode: code, Message: err.Error()}
+		r.out.emit(result.Error)
+	}
+	r.out.emit(result)
+	return code
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"oh-my-rime-cli/internal/constants"
+	"oh-my-rime-cli/internal/downloader"
+	"oh-my-rime-cli/internal/updater"
+)
+
+// RecoverInterruptedUpdates 检查遗留的更新日志，询问用户回滚或继续。
+// options 按当前配置创建更新选项，继续更新时使用
+func RecoverInterruptedUpdates(reader *bufio.Reader, options func(source, targetDir string) updater.Options) {
+	pending, err := updater.PendingUpdates()
+	if err != nil {
+		fmt.Printf("检查未完成的更新失败: %v\n", err)
+		return
+	}
+
+	for _, update := range pending {
+		fmt.Println("\n==============================")
+		fmt.Println(" ⚠️  检测到上次未完成的更新 ")
+		fmt.Println("==============================")
+		fmt.Println("操作: ", update.OperationName())
+		fmt.Println("目标目录: ", update.TargetDir)
+		fmt.Println("开始时间: ", update.StartedAt.Format("2006-01-02 15:04:05"))
+		if update.BackupDir != "" {
+			fmt.Println("备份目录: ", update.BackupDir)
+		}
+		fmt.Printf("已写入文件: %d 个\n", len(update.Written))
+		fmt.Println("------------------------------")
+		fmt.Println("  [r] 回滚到更新前状态")
+		fmt.Println("  [c] 重新下载并继续更新")
+		fmt.Println("  [i] 忽略并删除该记录")
+		fmt.Println("  [s] 暂不处理（直接回车）")
+		fmt.Print("请输入选项 (r/c/i/s)：")
+
+		choice, _ := reader.ReadString('\n')
+		switch strings.TrimSpace(choice) {
+		case "r":
+			if err := updater.RollbackPendingUpdate(update); err != nil {
+				fmt.Printf("回滚失败: %v\n", err)
+			} else {
+				fmt.Println("✅ 回滚完成")
+			}
+		case "c":
+			resumeUpdate(update, options(ResumeSource(update), update.TargetDir))
+		case "i":
+			if err := updater.DiscardPendingUpdate(update); err != nil {
+				fmt.Printf("删除更新记录失败: %v\n", err)
+			}
+		default:
+			fmt.Println("已跳过，下次启动时将再次提示")
+		}
+	}
+}
+
+func resumeUpdate(update *updater.PendingUpdate, opts updater.Options) {
+	data := DownloadForResume(update, nil)
+	if data == nil {
+		fmt.Println("下载失败，更新记录已保留，可稍后重试或选择回滚")
+		return
+	}
+	if err := updater.ResumePendingUpdate(update, data, opts); err != nil {
+		fmt.Printf("继续更新失败: %v\n", err)
+	}
+}
+
+// DownloadForResume 重新下载中断的更新所需的数据，词库更新只下载 dicts 目录
+func DownloadForResume(update *updater.PendingUpdate, callback downloader.ProgressCallback) []byte {
+	if update.Operation == updater.OperationDict {
+		return downloader.DownloadZipEntries(ResumeSource(update), updater.IsDictEntry, callback)
+	}
+	return downloader.DownloadWithCallback(ResumeSource(update), callback)
+}
+
+// ResumeSource 返回继续更新时使用的下载地址
+func ResumeSource(update *updater.PendingUpdate) string {
+	if update.Source != "" {
+		return update.Source
+	}
+	if update.Operation == updater.OperationModel {
+		return constants.WanXiangGRA
+	}
+	return constants.OhMyRimeRepo
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"oh-my-rime-cli/internal/system"
+	"oh-my-rime-cli/internal/updater"
+)
+
+func noOptions(source, targetDir string) updater.Options {
+	return updater.Options{}
+}
+
+func TestRecoverInterruptedUpdatesRequiresExplicitRollback(t *testing.T) {
+	t.Setenv("OH_MY_RIME_CONFIG_DIR", t.TempDir())
+	targetDir := filepath.Join(t.TempDir(), "rime")
+	written := filepath.Join(targetDir, "default.yaml")
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(written, []byte("half"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// 更新前目标目录不存在（无备份），回滚会删除已写入的文件
+	header, _ := json.Marshal(updater.PendingUpdate{Operation: updater.OperationMainScheme, TargetDir: targetDir, StartedAt: time.Now()})
+	journalDir := filepath.Join(system.AppConfigDir(), "journal")
+	if err := os.MkdirAll(journalDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(journalDir, "test.journal"), []byte(string(header)+"\n"+written+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 直接回车暂不处理
+	RecoverInterruptedUpdates(bufio.NewReader(strings.NewReader("\n")), noOptions)
+	if _, err := os.Stat(written); err != nil {
+		t.Fatalf("empty input rolled back the update: %v", err)
+	}
+	if pending, err := updater.PendingUpdates(); err != nil || len(pending) != 1 {
+		t.Fatalf("PendingUpdates() = %v, %v; want the update kept", pending, err)
+	}
+
+	RecoverInterruptedUpdates(bufio.NewReader(strings.NewReader("r\n")), noOptions)
+	if _, err := os.Stat(written); !os.IsNotExist(err) {
+		t.Fatalf("written file after rollback: %v; want removed", err)
+	}
+}
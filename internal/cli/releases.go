@@ -0,0 +1,195 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"oh-my-rime-cli/internal/downloader"
+	"oh-my-rime-cli/internal/releases"
+	"oh-my-rime-cli/internal/updater"
+)
+
+// releaseAsset 可以选择版本的资源
+type releaseAsset struct {
+	// Asset 版本记录中的资源名
+	Asset string
+	Name  string
+	// URL 最新版本的下载地址
+	URL string
+}
+
+// releaseAsset 返回 scheme、model、dict 对应的资源。模型按 --model-name 选择，默认为第一个模型
+func (r *runner) releaseAsset(kind string) (releaseAsset, error) {
+	switch kind {
+	case "scheme":
+		return releaseAsset{Asset: updater.OperationMainScheme, Name: "薄荷方案", URL: r.config.SchemeURL()}, nil
+	case "dict":
+		return releaseAsset{Asset: updater.OperationDict, Name: "万象词库", URL: r.config.SchemeURL()}, nil
+	case "model":
+		models := r.config.Models()
+		model := models[0]
+		for _, m := range models {
+			if m.FileName == r.flags.ModelName {
+				model = m
+			}
+		}
+		name, err := ModelFileName(r.flags.ModelName, model.FileName)
+		if err != nil {
+			return releaseAsset{}, err
+		}
+		return releaseAsset{Asset: updater.ModelAsset(name), Name: model.Name, URL: model.URL}, nil
+	}
+	return releaseAsset{}, fmt.Errorf("%w: 未知的资源类型 %s（可选 scheme、model、dict）", errUsage, kind)
+}
+
+// runReleaseCommand 执行 releases、changelog、pin 与 unpin 子命令
+func (r *runner) runReleaseCommand(command string, args []string) error {
+	kind := "scheme"
+	switch {
+	case command == "releases" && len(args) > 1:
+		return fmt.Errorf("%w: 用法 releases [scheme|model|dict]", errUsage)
+	case command == "pin" && len(args) != 2:
+		return fmt.Errorf("%w: 用法 pin scheme|model|dict <版本>", errUsage)
+	case command == "unpin" && len(args) != 1:
+		return fmt.Errorf("%w: 用法 unpin scheme|model|dict", errUsage)
+	case command == "changelog" && len(args) > 1:
+		return fmt.Errorf("%w: 用法 changelog [scheme|model|dict]", errUsage)
+	}
+	if len(args) > 0 {
+		kind = args[0]
+	}
+	asset, err := r.releaseAsset(kind)
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "releases":
+		return r.listReleases(asset)
+	case "pin":
+		return r.pin(asset, args[1])
+	case "changelog":
+		return r.runChangelog(asset)
+	}
+	return r.unpin(asset)
+}
+
+// listReleases 列出资源的发布版本，并标出最新版本
+func (r *runner) listReleases(asset releaseAsset) error {
+	source, fileName, err := r.releaseSource(asset.URL)
+	if err != nil {
+		return err
+	}
+	list, err := source.Releases()
+	if err != nil {
+		return downloadError(err.Error())
+	}
+	r.releaseList = list
+
+	fmt.Printf("%s（%s）的发布版本：\n", asset.Name, fileName)
+	if cached, ok := source.(*releases.Cached); ok && cached.Offline {
+		fmt.Printf("（无法连接发布源，以下为 %s 缓存的列表）\n", cached.FetchedAt.Local().Format("2006-01-02 15:04"))
+	}
+	if len(list) == 0 {
+		fmt.Println("  没有找到发布版本")
+	}
+	for i, release := range list {
+		var details []string
+		if !release.PublishedAt.IsZero() {
+			details = append(details, release.PublishedAt.Local().Format("2006-01-02"))
+		}
+		if file, ok := release.FindAsset(fileName); ok && file.Size > 0 {
+			details = append(details, downloader.FormatBytes(file.Size))
+		} else if len(release.Assets) > 0 && !ok {
+			details = append(details, "不含 "+fileName)
+		}
+		if i == 0 {
+			details = append(details, "最新")
+		}
+		fmt.Printf("  %-20s %s\n", release.Tag, strings.Join(details, "  "))
+	}
+	fmt.Printf("\n使用 --tag <版本> 安装指定版本，或用 pin 命令固定版本\n")
+	return nil
+}
+
+// pin 把各目标目录中的资源固定在 tag 版本。无法获取发布列表时只给出警告
+func (r *runner) pin(asset releaseAsset, tag string) error {
+	if _, err := releases.AssetURL(asset.URL, tag); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if err := r.checkRelease(asset, tag); err != nil {
+		return err
+	}
+
+	targets, err := r.targets()
+	if err != nil {
+		return err
+	}
+	r.results = nil
+	var failed int
+	for _, t := range targets {
+		err := updater.SetPin(t.Dir, asset.Asset, tag)
+		result := newTargetResult(t.Dir, err)
+		if err != nil {
+			failed++
+			fmt.Printf("❌ %s: 固定版本失败: %v\n", t.Dir, err)
+		} else {
+			result.Pinned = tag
+			fmt.Printf("📌 %s: %s已固定在 %s\n", t.Dir, asset.Name, tag)
+		}
+		r.results = append(r.results, result)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d/%d 个目录固定版本失败", failed, len(targets))
+	}
+	fmt.Println("运行 update 安装该版本；在解除或修改固定前，update 不会安装其他版本")
+	return nil
+}
+
+// checkRelease 检查发布源中是否有 tag 版本及其中的文件
+func (r *runner) checkRelease(asset releaseAsset, tag string) error {
+	source, fileName, err := r.releaseSource(asset.URL)
+	if err != nil {
+		fmt.Printf("⚠️  %v，未检查版本 %s 是否存在\n", err, tag)
+		return nil
+	}
+	list, err := source.Releases()
+	if err != nil {
+		fmt.Printf("⚠️  %v，未检查版本 %s 是否存在\n", err, tag)
+		return nil
+	}
+	for _, release := range list {
+		if release.Tag != tag {
+			continue
+		}
+		if _, ok := release.FindAsset(fileName); !ok && len(release.Assets) > 0 {
+			return fmt.Errorf("%w: 版本 %s 中没有 %s", errUsage, tag, fileName)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: 没有找到版本 %s，可用 releases 命令查看可用版本", errUsage, tag)
+}
+
+// unpin 解除各目标目录中资源的固定版本
+func (r *runner) unpin(asset releaseAsset) error {
+	targets, err := r.targets()
+	if err != nil {
+		return err
+	}
+	r.results = nil
+	var failed int
+	for _, t := range targets {
+		err := updater.SetPin(t.Dir, asset.Asset, "")
+		r.results = append(r.results, newTargetResult(t.Dir, err))
+		if err != nil {
+			failed++
+			fmt.Printf("❌ %s: 解除固定失败: %v\n", t.Dir, err)
+		} else {
+			fmt.Printf("%s: %s已解除固定，update 将安装最新版本\n", t.Dir, asset.Name)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d/%d 个目录解除固定失败", failed, len(targets))
+	}
+	return nil
+}
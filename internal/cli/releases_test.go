@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"oh-my-rime-cli/internal/releases"
+	"oh-my-rime-cli/internal/updater"
+)
+
+func TestRunPinnedTargetSkipsUpdate(t *testing.T) {
+	t.Setenv("OH_MY_RIME_CONFIG_DIR", t.TempDir())
+	server := newReleaseServer(t)
+	t.Setenv("OH_MY_RIME_SOURCES_SCHEME", server.URL+"/releases/download/latest/oh-my-rime.zip")
+	targetDir := t.TempDir()
+	installed := func() string {
+		t.Helper()
+		versions, err := updater.InstalledVersions(targetDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return versions[updater.OperationMainScheme].Version
+	}
+
+	// 测试服务器不是 cnb.cool 或 GitHub，无法列出版本，只给出警告
+	if code, _ := runJSON(t, "pin", "scheme", "v1", "--target", targetDir); code != ExitOK {
+		t.Fatalf("pin exit code = %d", code)
+	}
+	server.version = "v2"
+	if code, _ := runJSON(t, "update", "scheme", "--yes", "--target", targetDir); code != ExitOK {
+		t.Fatalf("update exit code = %d", code)
+	}
+	if got := installed(); got != "v1" {
+		t.Fatalf("installed version = %q; want pinned v1", got)
+	}
+
+	code, events := runJSON(t, "update", "scheme", "--yes", "--target", targetDir)
+	if code != ExitOK {
+		t.Fatalf("second update exit code = %d", code)
+	}
+	result := events[len(events)-1]
+	if result["ok"] != true || result["targets"] != nil {
+		t.Fatalf("unexpected result: %v", result)
+	}
+	for _, event := range events {
+		if event["type"] == "progress" {
+			t.Fatalf("pinned target was downloaded again: %v", event)
+		}
+	}
+
+	if code, _ := runJSON(t, "update", "scheme", "--yes", "--target", targetDir, "--tag", "v2"); code != ExitUsage {
+		t.Fatalf("--tag different from pin exit code = %d; want %d", code, ExitUsage)
+	}
+
+	if code, _ := runJSON(t, "unpin", "scheme", "--target", targetDir); code != ExitOK {
+		t.Fatalf("unpin exit code = %d", code)
+	}
+	if code, _ := runJSON(t, "update", "scheme", "--yes", "--target", targetDir); code != ExitOK {
+		t.Fatalf("update after unpin exit code = %d", code)
+	}
+	if got := installed(); got != "v2" {
+		t.Fatalf("installed version = %q; want latest v2", got)
+	}
+}
+
+func TestPinChecksReleaseExists(t *testing.T) {
+	r := newRunner(Flags{Targets: listFlag{t.TempDir()}})
+	r.releaseSource = func(url string) (releases.Source, string, error) {
+		return releases.Static{
+			{Tag: "v2", PublishedAt: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), Assets: []releases.Asset{{Name: "oh-my-rime.zip", Size: 2048}}},
+			{Tag: "v1", PublishedAt: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), Assets: []releases.Asset{{Name: "other.zip"}}},
+		}, "oh-my-rime.zip", nil
+	}
+
+	if err := r.runReleaseCommand("releases", nil); err != nil {
+		t.Fatalf("releases returned error: %v", err)
+	}
+	if len(r.releaseList) != 2 {
+		t.Fatalf("releaseList = %v", r.releaseList)
+	}
+	if err := r.runReleaseCommand("pin", []string{"scheme", "v3"}); !errors.Is(err, errUsage) {
+		t.Fatalf("pin unknown tag error = %v; want usage error", err)
+	}
+	if err := r.runReleaseCommand("pin", []string{"scheme", "v1"}); !errors.Is(err, errUsage) {
+		t.Fatalf("pin tag without asset error = %v; want usage error", err)
+	}
+	if err := r.runReleaseCommand("pin", []string{"scheme", "v2"}); err != nil {
+		t.Fatalf("pin returned error: %v", err)
+	}
+	pins, err := updater.Pins(r.flags.Targets[0])
+	if err != nil || pins[updater.OperationMainScheme] != "v2" {
+		t.Fatalf("pins = %v, %v", pins, err)
+	}
+}
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"oh-my-rime-cli/internal/deploy"
+	"oh-my-rime-cli/internal/rimelog"
+)
+
+// 部署在前端进程中异步进行，检查日志前稍作等待
+const deploySettleDelay = 3 * time.Second
+
+// CheckRimeLog 检查前端的 Rime 日志，列出 since 之后关于方案、词库与 Lua 的警告和错误。
+// since 为零值时检查整个日志
+func CheckRimeLog(frontend deploy.Frontend, since time.Time) {
+	report, err := rimelog.Inspect(frontend, since)
+	if err != nil {
+		fmt.Printf("检查 Rime 日志失败: %v\n", err)
+		return
+	}
+	if len(report.LogFiles) == 0 {
+		fmt.Println("未找到 Rime 日志，可能尚未部署过")
+		return
+	}
+
+	for _, path := range report.LogFiles {
+		fmt.Printf("Rime 日志: %s\n", path)
+	}
+	if len(report.Entries) == 0 {
+		fmt.Println("✅ 未发现方案、词库或 Lua 相关的警告和错误")
+		return
+	}
+	fmt.Printf("⚠️  发现 %d 条方案、词库或 Lua 相关的警告和错误：\n", len(report.Entries))
+	for _, entry := range report.Entries {
+		fmt.Printf("  %s\n", entry)
+	}
+}
+
+// CheckRimeLogAfterDeploy 等待部署完成后检查本次更新以来的日志
+func CheckRimeLogAfterDeploy(frontend deploy.Frontend, started time.Time) {
+	fmt.Println("正在等待部署完成...")
+	time.Sleep(deploySettleDelay)
+	CheckRimeLog(frontend, started)
+}
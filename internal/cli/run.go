@@ -0,0 +1,351 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"oh-my-rime-cli/internal/config"
+	"oh-my-rime-cli/internal/deploy"
+	"oh-my-rime-cli/internal/downloader"
+	"oh-my-rime-cli/internal/releases"
+	"oh-my-rime-cli/internal/updater"
+)
+
+// Flags 命令行参数
+type Flags struct {
+	// zip 文件名编码，用于解压未标记 UTF-8 的方案包
+	ZipEncoding string
+	// 词库选择规则，设置后不再交互询问
+	DictInclude string
+	DictExclude string
+	// 词库同步方式：merge 保留本地多余的词库，mirror 删除上游已移除的词库
+	DictSync string
+	DictKeep string
+	// 模型文件名，默认取下载地址或 Content-Disposition 中的文件名
+	ModelName string
+	// 模型更新后启用语法模型的方案，设置后不再交互询问
+	GrammarSchemas string
+	// 更新完成后自动重新部署，不再询问
+	Deploy bool
+	// 钩子脚本目录，文件名与阶段同名（pre-download、pre-extract、post-extract、post-failure）
+	HooksDir     string
+	HookRollback bool
+	// Rime 用户目录，可指定多个，设置后不再询问
+	Targets listFlag
+	// 输入法前端，决定默认的用户目录与部署方式，可指定多个
+	Frontends listFlag
+	// 不进行任何交互，全部使用默认选择
+	Yes bool
+	// 更新前不创建备份
+	NoBackup bool
+	// 输出格式：text 或 json
+	Output string
+	// 下载使用的代理，覆盖配置文件
+	Proxy string
+	// 安装指定的发布版本，而不是最新版本
+	Tag string
+	// 解压资源限制，覆盖配置文件
+	MaxTotalSize        string
+	MaxFileSize         string
+	MaxEntries          int
+	MaxCompressionRatio float64
+	// 目标目录被其他进程锁定时的最长等待时间，覆盖配置文件
+	LockWait string
+}
+
+// listFlag 可重复指定或以逗号分隔的参数
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, SplitGlobs(value)...)
+	return nil
+}
+
+// newFlagSet 创建命令行参数解析器，解析结果写入 f
+func newFlagSet(f *Flags, output io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("oh-my-rime", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.StringVar(&f.ZipEncoding, "zip-encoding", "auto", "zip 文件名编码（auto、utf-8、gbk、gb18030、big5、shift-jis）")
+	fs.StringVar(&f.DictInclude, "dict-include", "", "只安装匹配的词库，逗号分隔的通配符，如 'base*,*.ext.dict.yaml'")
+	fs.StringVar(&f.DictExclude, "dict-exclude", "", "不安装匹配的词库，逗号分隔的通配符")
+	fs.StringVar(&f.DictSync, "dict-sync", "merge", "词库同步方式（merge、mirror）")
+	fs.StringVar(&f.DictKeep, "dict-keep", "", "mirror 模式下保留的本地词库，逗号分隔的通配符（默认 *.custom.dict.yaml,custom_*）")
+	fs.StringVar(&f.ModelName, "model-name", "", "模型保存的文件名（需以 .gram 结尾），覆盖从下载地址推断的文件名")
+	fs.StringVar(&f.GrammarSchemas, "grammar-schemas", "", "更新模型后在这些方案中启用语法模型，逗号分隔的方案 ID（all 全部、none 跳过、revert 撤销之前的修改）")
+	fs.BoolVar(&f.Deploy, "deploy", false, "更新完成后自动重新部署 Rime（ibus、fcitx5、fcitx、鼠须管、小狼毫）")
+	fs.StringVar(&f.HooksDir, "hooks-dir", "", "钩子脚本目录（默认 "+updater.HooksDir()+"）")
+	fs.BoolVar(&f.HookRollback, "hook-rollback", false, "post-extract 钩子失败时恢复备份")
+	fs.Var(&f.Targets, "target", "Rime 用户目录，设置后不再询问；可重复或以逗号分隔，同时更新多个目录")
+	fs.Var(&f.Frontends, "frontend", "输入法前端（ibus、fcitx5、fcitx5-flatpak、fcitx、squirrel、weasel），未指定 --target 时使用其默认目录；可重复或以逗号分隔")
+	fs.BoolVar(&f.Yes, "yes", false, "不进行任何询问，全部使用默认选择（适用于脚本）")
+	fs.BoolVar(&f.NoBackup, "no-backup", false, "更新前不创建备份（更新失败时无法恢复）")
+	fs.StringVar(&f.Tag, "tag", "", "安装指定的发布版本（如 v1.2.3，可用 releases 命令查看），默认安装最新版本")
+	fs.StringVar(&f.Proxy, "proxy", "", "下载使用的代理，如 http://127.0.0.1:7890（默认读取配置文件或 HTTP_PROXY、HTTPS_PROXY 环境变量）")
+	fs.StringVar(&f.MaxTotalSize, "max-total-size", "", "解压后的总大小上限，如 4GB（默认读取配置文件，否则为 "+downloader.FormatBytes(updater.DefaultExtractLimits.MaxTotalSize)+"）")
+	fs.StringVar(&f.MaxFileSize, "max-file-size", "", "解压后单个文件的大小上限，如 1GB（默认读取配置文件，否则为 "+downloader.FormatBytes(updater.DefaultExtractLimits.MaxFileSize)+"）")
+	fs.IntVar(&f.MaxEntries, "max-entries", 0, fmt.Sprintf("更新包的文件数上限（默认读取配置文件，否则为 %d）", updater.DefaultExtractLimits.MaxEntries))
+	fs.Float64Var(&f.MaxCompressionRatio, "max-compression-ratio", 0, fmt.Sprintf("单个文件的压缩比上限（默认读取配置文件，否则为 %g）", updater.DefaultExtractLimits.MaxCompressionRatio))
+	fs.StringVar(&f.LockWait, "lock-wait", "", "目标目录正被其他进程更新时最多等待多久，如 30s、2m（默认读取配置文件，否则立即报错）")
+	fs.StringVar(&f.Output, "output", OutputText, "输出格式（text、json）；json 时标准输出为逐行的 JSON 事件，其余提示写入标准错误")
+	fs.Usage = func() {
+		fmt.Fprintln(output, "用法:")
+		fmt.Fprintln(output, "  oh-my-rime [选项]                         进入交互菜单")
+		fmt.Fprintln(output, "  oh-my-rime update scheme [选项]           更新薄荷方案")
+		fmt.Fprintln(output, "  oh-my-rime update model [选项]            更新万象模型")
+		fmt.Fprintln(output, "  oh-my-rime update dict [选项]             更新万象词库")
+		fmt.Fprintln(output, "  oh-my-rime update custom <url> [选项]     从 zip 或 gram 地址更新")
+		fmt.Fprintln(output, "  oh-my-rime check [选项]                   检查已安装的方案、词库与模型是否有新版本（别名 status）")
+		fmt.Fprintln(output, "  oh-my-rime releases [scheme|model|dict]   列出可安装的发布版本")
+		fmt.Fprintln(output, "  oh-my-rime changelog [scheme|model|dict]  查看更新到最新（或固定）版本的发布说明，离线时读取缓存")
+		fmt.Fprintln(output, "  oh-my-rime pin scheme|model|dict <版本>  把目标目录固定在指定版本，update 只会安装该版本")
+		fmt.Fprintln(output, "  oh-my-rime unpin scheme|model|dict       解除固定，恢复更新到最新版本")
+		fmt.Fprintln(output, "  oh-my-rime config path|get [项]|set <项> <值>|edit")
+		fmt.Fprintln(output, "                                            查看或修改配置文件 "+config.Path())
+		fmt.Fprintln(output, "\n选项:")
+		fs.PrintDefaults()
+		fmt.Fprintln(output, "\n退出码:")
+		fmt.Fprintln(output, "  0 成功  1 其他错误  2 参数错误  3 下载失败  4 更新包校验失败  5 更新失败并已回滚  6 回滚失败")
+	}
+	return fs
+}
+
+// parseArgs 解析参数，选项可以出现在子命令之前或之后，返回子命令及其参数
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// limits 命令行指定的解压资源限制，未指定的项为零值
+func (f Flags) limits() config.Limits {
+	return config.Limits{
+		MaxTotalSize:        f.MaxTotalSize,
+		MaxFileSize:         f.MaxFileSize,
+		MaxEntries:          f.MaxEntries,
+		MaxCompressionRatio: f.MaxCompressionRatio,
+	}
+}
+
+// validate 检查参数取值
+func (f Flags) validate() error {
+	if err := updater.ValidateNameEncoding(f.ZipEncoding); err != nil {
+		return err
+	}
+	if _, err := updater.ParseDictSyncMode(f.DictSync); err != nil {
+		return err
+	}
+	if f.ModelName != "" {
+		if err := updater.ValidateModelName(f.ModelName); err != nil {
+			return err
+		}
+	}
+	for _, frontend := range f.Frontends {
+		if _, err := deploy.ParseFrontend(frontend); err != nil {
+			return err
+		}
+	}
+	if len(f.Targets) > 0 && len(f.Frontends) > 0 && len(f.Targets) != len(f.Frontends) {
+		return fmt.Errorf("同时指定 --target 与 --frontend 时数量必须一致（按顺序对应）")
+	}
+	if err := f.limits().Validate(); err != nil {
+		return err
+	}
+	if _, err := config.ParseLockWait(f.LockWait); err != nil {
+		return err
+	}
+	if f.Output != OutputText && f.Output != OutputJSON {
+		return fmt.Errorf("不支持的输出格式: %s（可选 text、json）", f.Output)
+	}
+	return nil
+}
+
+// Run 解析命令行参数并执行。指定了子命令时直接执行，否则进入交互菜单。返回进程退出码
+func Run(args []string) int {
+	var flags Flags
+	fs := newFlagSet(&flags, os.Stderr)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		// flag 已输出错误和用法
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+		return ExitUsage
+	}
+
+	r := newRunner(flags)
+	if flags.Output == OutputJSON {
+		defer r.useJSONOutput()()
+	}
+	command := strings.Join(positional, " ")
+	if err := flags.validate(); err != nil {
+		return r.finish(command, fmt.Errorf("%w: %v", errUsage, err))
+	}
+
+	if len(positional) == 0 {
+		if r.out != nil {
+			return r.finish(command, fmt.Errorf("%w: --output json 需要指定子命令", errUsage))
+		}
+		if err := r.loadConfig(); err != nil {
+			return r.finish(command, err)
+		}
+		return r.finish(command, r.runMenu())
+	}
+
+	err = r.runCommand(positional)
+	if errors.Is(err, errUsage) && r.out == nil {
+		fmt.Fprintln(os.Stderr, err)
+		fs.Usage()
+		return ExitUsage
+	}
+	return r.finish(command, err)
+}
+
+// runCommand 执行子命令
+func (r *runner) runCommand(positional []string) error {
+	switch positional[0] {
+	case "update":
+	case "check", "status":
+		if len(positional) > 1 {
+			return fmt.Errorf("%w: 多余的参数 %s", errUsage, strings.Join(positional[1:], " "))
+		}
+		if err := r.loadConfig(); err != nil {
+			return err
+		}
+		return r.runCheck()
+	case "releases", "changelog", "pin", "unpin":
+		if err := r.loadConfig(); err != nil {
+			return err
+		}
+		return r.runReleaseCommand(positional[0], positional[1:])
+	case "config":
+		// 配置文件有误时仍需能够查看和修改，不预先加载
+		return r.runConfig(positional[1:])
+	case "help":
+		return fmt.Errorf("%w: 请使用 -h 查看帮助", errUsage)
+	default:
+		return fmt.Errorf("%w: 未知命令 %s", errUsage, positional[0])
+	}
+
+	if len(positional) < 2 {
+		return fmt.Errorf("%w: update 需要指定 scheme、model、dict 或 custom", errUsage)
+	}
+	kind, rest := positional[1], positional[2:]
+	var want int
+	switch kind {
+	case "scheme", "model", "dict":
+	case "custom":
+		want = 1
+	default:
+		return fmt.Errorf("%w: 未知的更新类型 %s（可选 scheme、model、dict、custom）", errUsage, kind)
+	}
+	if len(rest) != want {
+		if want == 1 {
+			return fmt.Errorf("%w: update custom 需要一个 zip 或 gram 文件的 URL", errUsage)
+		}
+		return fmt.Errorf("%w: 多余的参数 %s", errUsage, strings.Join(rest, " "))
+	}
+
+	if err := r.loadConfig(); err != nil {
+		return err
+	}
+	r.warnPendingUpdates()
+	switch kind {
+	case "scheme":
+		return r.updateScheme()
+	case "model":
+		return r.updateModel()
+	case "dict":
+		return r.updateDict()
+	default:
+		return r.updateCustom(rest[0])
+	}
+}
+
+// warnPendingUpdates 子命令模式下不处理中断的更新，只给出提示
+func (r *runner) warnPendingUpdates() {
+	pending, err := updater.PendingUpdates()
+	if err != nil {
+		fmt.Printf("检查未完成的更新失败: %v\n", err)
+		return
+	}
+	for _, update := range pending {
+		fmt.Printf("⚠️  检测到未完成的%s（%s），请运行交互菜单回滚或继续\n", update.OperationName(), update.TargetDir)
+	}
+}
+
+// runner 保存一次运行的参数与标准输入
+type runner struct {
+	flags  Flags
+	reader *bufio.Reader
+	// out JSON 输出，文本输出时为 nil
+	out *jsonOutput
+	// results 最近一次操作中各目标目录的结果，写入 JSON 结果
+	results []targetResult
+	// config 配置文件（已应用环境变量覆盖），加载前为空配置
+	config *config.Config
+	// configValues config get 的结果，写入 JSON 结果
+	configValues map[string]string
+	// checks check 的结果，写入 JSON 结果
+	checks []AssetCheck
+	// releaseList releases 列出的发布，或 changelog、update 输出的发布说明，写入 JSON 结果
+	releaseList []releases.Release
+	// releaseSource 根据下载地址返回发布源与文件名，测试时替换
+	releaseSource func(url string) (releases.Source, string, error)
+}
+
+func newRunner(flags Flags) *runner {
+	return &runner{
+		flags:         flags,
+		reader:        bufio.NewReader(os.Stdin),
+		config:        &config.Config{},
+		releaseSource: releases.CachedForURL,
+	}
+}
+
+// loadConfig 加载配置文件，检查其中的前端并设置下载代理
+func (r *runner) loadConfig() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	for _, frontend := range cfg.Frontends {
+		if _, err := deploy.ParseFrontend(frontend); err != nil {
+			return fmt.Errorf("配置文件 %s 无效: %v", config.Path(), err)
+		}
+	}
+	proxy := cfg.Proxy
+	if r.flags.Proxy != "" {
+		proxy = r.flags.Proxy
+	}
+	if err := downloader.SetProxy(proxy); err != nil {
+		return err
+	}
+	r.config = cfg
+	return nil
+}
+
+// prompter 返回用于询问的输入，--yes 时返回 nil 表示不询问
+func (r *runner) prompter() *bufio.Reader {
+	if r.flags.Yes {
+		return nil
+	}
+	return r.reader
+}
@@ -0,0 +1,376 @@
+package cli
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"oh-my-rime-cli/internal/updater"
+)
+
+func TestParseArgsAllowsFlagsAfterSubcommand(t *testing.T) {
+	var flags Flags
+	fs := newFlagSet(&flags, io.Discard)
+	positional, err := parseArgs(fs, []string{"--yes", "update", "custom", "https://example.com/a.zip", "--target", "/tmp/rime", "--no-backup"})
+	if err != nil {
+		t.Fatalf("parseArgs returned error: %v", err)
+	}
+	if want := []string{"update", "custom", "https://example.com/a.zip"}; !reflect.DeepEqual(positional, want) {
+		t.Fatalf("positional = %v; want %v", positional, want)
+	}
+	if !flags.Yes || !flags.NoBackup || !reflect.DeepEqual([]string(flags.Targets), []string{"/tmp/rime"}) {
+		t.Fatalf("flags = %+v", flags)
+	}
+}
+
+func TestRunRejectsInvalidArguments(t *testing.T) {
+	tests := [][]string{
+		{"--frontend", "fcitx4", "update", "scheme"},
+		{"--no-such-flag"},
+		{"install"},
+		{"update"},
+		{"update", "everything"},
+		{"update", "custom"},
+		{"update", "scheme", "extra"},
+	}
+	for _, args := range tests {
+		if code := Run(args); code != ExitUsage {
+			t.Errorf("Run(%q) = %d; want %d", args, code, ExitUsage)
+		}
+	}
+}
+
+func TestRunUpdateCustomNonInteractive(t *testing.T) {
+	t.Setenv("OH_MY_RIME_CONFIG_DIR", t.TempDir())
+
+	server := zipServer(t, map[string]string{"default.yaml": "config_version: new\n"})
+
+	parentDir := t.TempDir()
+	targetDir := filepath.Join(parentDir, "rime")
+	args := []string{"update", "custom", server.URL + "/oh-my-rime.zip", "--target", targetDir, "--yes", "--no-backup"}
+	if code := Run(args); code != ExitOK {
+		t.Fatalf("Run = %d; want %d", code, ExitOK)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(targetDir, "default.yaml")); err != nil || string(data) != "config_version: new\n" {
+		t.Fatalf("default.yaml = %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(parentDir, "rime.backups")); !os.IsNotExist(err) {
+		t.Fatalf("backup created with --no-backup; stat error: %v", err)
+	}
+}
+
+func TestRunRequiresTargetWithYes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows 从注册表读取目标目录")
+	}
+	t.Setenv("OH_MY_RIME_CONFIG_DIR", t.TempDir())
+	if code := Run([]string{"update", "custom", "https://example.invalid/a.zip", "--yes"}); code != ExitUsage {
+		t.Fatalf("Run = %d; want %d", code, ExitUsage)
+	}
+}
+
+// zipServer 返回提供 zip 下载的测试服务器，其他路径返回 404
+func zipServer(t *testing.T, files map[string]string) *httptest.Server {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, body := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(body))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, ".zip") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/zip")
+		w.Write(buf.Bytes())
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// runJSON 以 JSON 输出运行命令，返回退出码和解析后的事件
+func runJSON(t *testing.T, args ...string) (int, []map[string]interface{}) {
+	t.Helper()
+	out, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	stdout := os.Stdout
+	os.Stdout = out
+	code := Run(append(args, "--output", "json"))
+	os.Stdout = stdout
+
+	data, err := os.ReadFile(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var event map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("stdout line %q is not JSON: %v", line, err)
+		}
+		events = append(events, event)
+	}
+	return code, events
+}
+
+func TestRunJSONOutput(t *testing.T) {
+	t.Setenv("OH_MY_RIME_CONFIG_DIR", t.TempDir())
+	server := zipServer(t, map[string]string{"default.yaml": "new"})
+	targetDir := filepath.Join(t.TempDir(), "rime")
+
+	code, events := runJSON(t, "update", "custom", server.URL+"/a.zip", "--target", targetDir, "--yes")
+	if code != ExitOK {
+		t.Fatalf("exit code = %d; want %d", code, ExitOK)
+	}
+	var types []string
+	for _, event := range events {
+		if event["type"] != "progress" {
+			types = append(types, event["type"].(string))
+		}
+	}
+	if want := []string{updater.EventFileWritten, "result"}; !reflect.DeepEqual(types, want) {
+		t.Fatalf("event types = %v; want %v", types, want)
+	}
+	result := events[len(events)-1]
+	if result["ok"] != true || result["targetDir"] != targetDir || result["command"] != "update custom "+server.URL+"/a.zip" {
+		t.Fatalf("result = %v", result)
+	}
+}
+
+func TestRunExitCodes(t *testing.T) {
+	t.Setenv("OH_MY_RIME_CONFIG_DIR", t.TempDir())
+	server := zipServer(t, map[string]string{"../escape.yaml": "escape"})
+	targetDir := filepath.Join(t.TempDir(), "rime")
+
+	tests := []struct {
+		args []string
+		code int
+		name string
+	}{
+		{[]string{"update", "custom", server.URL + "/missing.gram"}, ExitNetwork, CodeNetwork},
+		{[]string{"update", "custom", server.URL + "/escape.zip"}, ExitValidation, CodeValidation},
+		{[]string{"update", "custom", "ftp://example.com/a.txt"}, ExitUsage, CodeUsage},
+		{[]string{}, ExitUsage, CodeUsage},
+	}
+	for _, tt := range tests {
+		code, events := runJSON(t, append(tt.args, "--target", targetDir, "--yes")...)
+		result := events[len(events)-1]
+		errorInfo, _ := result["error"].(map[string]interface{})
+		if code != tt.code || result["type"] != "result" || errorInfo["code"] != tt.name {
+			t.Errorf("Run(%q) = %d, %v; want %d %s", tt.args, code, result, tt.code, tt.name)
+		}
+	}
+}
+
+func TestExtractLimitsFromConfigAndFlags(t *testing.T) {
+	t.Setenv("OH_MY_RIME_CONFIG_DIR", t.TempDir())
+	t.Setenv("OH_MY_RIME_LIMITS_MAX_ENTRIES", "1")
+	server := zipServer(t, map[string]string{"default.yaml": "new", "mint.schema.yaml": "new"})
+	targetDir := filepath.Join(t.TempDir(), "rime")
+
+	if code, _ := runJSON(t, "update", "custom", server.URL+"/a.zip", "--target", targetDir, "--yes"); code != ExitValidation {
+		t.Fatalf("exit code with limits.max_entries=1 = %d; want %d", code, ExitValidation)
+	}
+	if code, _ := runJSON(t, "update", "custom", server.URL+"/a.zip", "--target", targetDir, "--yes", "--max-entries", "2"); code != ExitOK {
+		t.Fatalf("exit code with --max-entries 2 = %d; want %d", code, ExitOK)
+	}
+	if code, _ := runJSON(t, "update", "custom", server.URL+"/a.zip", "--target", targetDir, "--yes", "--max-file-size", "lots"); code != ExitUsage {
+		t.Fatalf("exit code with --max-file-size lots = %d; want %d", code, ExitUsage)
+	}
+}
+
+func TestHookCommandsFromConfig(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh commands")
+	}
+	t.Setenv("OH_MY_RIME_CONFIG_DIR", t.TempDir())
+	t.Setenv("OH_MY_RIME_HOOKS_COMMANDS_PRE_DOWNLOAD", "exit 1")
+	server := zipServer(t, map[string]string{"default.yaml": "new"})
+	targetDir := filepath.Join(t.TempDir(), "rime")
+
+	if code, _ := runJSON(t, "update", "custom", server.URL+"/a.zip", "--target", targetDir, "--yes"); code == ExitOK {
+		t.Fatal("update with failing pre-download command succeeded; want failure")
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "default.yaml")); !os.IsNotExist(err) {
+		t.Fatalf("default.yaml written despite failing pre-download command: %v", err)
+	}
+}
+
+func TestLockWaitFromConfigAndFlags(t *testing.T) {
+	t.Setenv("OH_MY_RIME_CONFIG_DIR", t.TempDir())
+	t.Setenv("OH_MY_RIME_LOCK_WAIT", "10s")
+	server := zipServer(t, map[string]string{"default.yaml": "new"})
+	targetDir := filepath.Join(t.TempDir(), "rime")
+	lockPath := targetDir + ".lock"
+	hostname, _ := os.Hostname()
+	content, _ := json.Marshal(map[string]interface{}{"pid": os.Getpid(), "hostname": hostname, "startedAt": time.Now()})
+	if err := os.WriteFile(lockPath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 命令行参数优先于配置：--lock-wait 0s 不等待
+	if code, _ := runJSON(t, "update", "custom", server.URL+"/a.zip", "--target", targetDir, "--yes", "--lock-wait", "0s"); code == ExitOK {
+		t.Fatal("update with held lock and --lock-wait 0s succeeded; want failure")
+	}
+	if code, _ := runJSON(t, "update", "custom", server.URL+"/a.zip", "--target", targetDir, "--yes", "--lock-wait", "soon"); code != ExitUsage {
+		t.Fatalf("exit code with --lock-wait soon = %d; want %d", code, ExitUsage)
+	}
+
+	// 配置的等待时间内锁被释放，更新继续
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		os.Remove(lockPath)
+	}()
+	if code, _ := runJSON(t, "update", "custom", server.URL+"/a.zip", "--target", targetDir, "--yes"); code != ExitOK {
+		t.Fatalf("exit code after lock released = %d; want %d", code, ExitOK)
+	}
+}
+
+func TestExitCodePrecedence(t *testing.T) {
+	rolledBack := fmt.Errorf("更新词库失败: %w", fmt.Errorf("磁盘已满: %w", updater.ErrRolledBack))
+	if code, _ := ExitCode(rolledBack); code != ExitRolledBack {
+		t.Errorf("ExitCode(rolled back) = %d; want %d", code, ExitRolledBack)
+	}
+	rollbackFailed := fmt.Errorf("%w；%w", updater.ErrInvalidModel, updater.ErrRollbackFailed)
+	if code, _ := ExitCode(rollbackFailed); code != ExitRollbackFailed {
+		t.Errorf("ExitCode(rollback failed) = %d; want %d", code, ExitRollbackFailed)
+	}
+	if code, _ := ExitCode(downloadError("下载失败")); code != ExitNetwork {
+		t.Errorf("ExitCode(download) = %d; want %d", code, ExitNetwork)
+	}
+}
+
+func TestRunUpdatesSeveralTargets(t *testing.T) {
+	t.Setenv("OH_MY_RIME_CONFIG_DIR", t.TempDir())
+	server := zipServer(t, map[string]string{"default.yaml": "new"})
+	goodDir := filepath.Join(t.TempDir(), "rime")
+	// 目标路径是普通文件，该目录的更新必然失败
+	badDir := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(badDir, []byte("not a directory"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	code, events := runJSON(t, "update", "custom", server.URL+"/a.zip", "--target", badDir+","+goodDir, "--yes")
+	if code == ExitOK {
+		t.Fatalf("exit code = %d; want failure", code)
+	}
+	if data, err := os.ReadFile(filepath.Join(goodDir, "default.yaml")); err != nil || string(data) != "new" {
+		t.Fatalf("default.yaml in good target = %q, %v", data, err)
+	}
+
+	var downloads int
+	for _, event := range events {
+		if event["type"] == updater.EventFileWritten && event["targetDir"] != goodDir {
+			t.Errorf("file-written event for %v", event["targetDir"])
+		}
+		if event["type"] == "progress" && event["downloaded"] == event["total"] {
+			downloads++
+		}
+	}
+	if downloads > 1 {
+		t.Errorf("downloaded %d times; want once", downloads)
+	}
+
+	result := events[len(events)-1]
+	targets, _ := result["targets"].([]interface{})
+	if result["ok"] != false || len(targets) != 2 {
+		t.Fatalf("result = %v", result)
+	}
+	bad, good := targets[0].(map[string]interface{}), targets[1].(map[string]interface{})
+	if bad["targetDir"] != badDir || bad["ok"] != false || good["targetDir"] != goodDir || good["ok"] != true {
+		t.Fatalf("targets = %v", targets)
+	}
+}
+
+func TestRunConfigCommands(t *testing.T) {
+	t.Setenv("OH_MY_RIME_CONFIG_DIR", t.TempDir())
+	t.Setenv("OH_MY_RIME_PROXY", "")
+
+	if code := Run([]string{"config", "set", "backup.keep", "5"}); code != ExitOK {
+		t.Fatalf("config set = %d; want %d", code, ExitOK)
+	}
+	code, events := runJSON(t, "config", "get", "backup.keep")
+	result := events[len(events)-1]
+	values, _ := result["config"].(map[string]interface{})
+	if code != ExitOK || values["backup.keep"] != "5" {
+		t.Fatalf("config get = %d, %v", code, result)
+	}
+
+	// 环境变量优先于配置文件
+	t.Setenv("OH_MY_RIME_BACKUP_KEEP", "9")
+	_, events = runJSON(t, "config", "get")
+	values, _ = events[len(events)-1]["config"].(map[string]interface{})
+	if values["backup.keep"] != "9" || values["proxy"] != "" {
+		t.Fatalf("config get = %v", values)
+	}
+
+	for _, args := range [][]string{
+		{"config", "set", "no.such.key", "1"},
+		{"config", "set", "frontends", "fcitx4"},
+		{"config", "set", "backup.disabled", "maybe"},
+		{"config", "get", "a", "b"},
+		{"config", "remove"},
+	} {
+		if code := Run(args); code != ExitUsage {
+			t.Errorf("Run(%q) = %d; want %d", args, code, ExitUsage)
+		}
+	}
+}
+
+func TestRunUsesConfigFile(t *testing.T) {
+	t.Setenv("OH_MY_RIME_CONFIG_DIR", t.TempDir())
+	server := zipServer(t, map[string]string{"default.yaml": "new", "default.custom.yaml": "upstream"})
+	targetDir := t.TempDir()
+	customPath := filepath.Join(targetDir, "default.custom.yaml")
+	if err := os.WriteFile(customPath, []byte("mine"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for key, value := range map[string]string{
+		"targets":         targetDir,
+		"protected":       "*.custom.yaml",
+		"backup.disabled": "true",
+		// 配置的方案地址经镜像规则替换为测试服务器
+		"sources.mirrors": "https://mirror.invalid/=" + server.URL + "/",
+	} {
+		if code := Run([]string{"config", "set", key, value}); code != ExitOK {
+			t.Fatalf("config set %s = %d", key, code)
+		}
+	}
+
+	if code := Run([]string{"update", "custom", "https://mirror.invalid/a.zip", "--yes"}); code != ExitOK {
+		t.Fatalf("Run = %d; want %d", code, ExitOK)
+	}
+	if data, err := os.ReadFile(filepath.Join(targetDir, "default.yaml")); err != nil || string(data) != "new" {
+		t.Fatalf("default.yaml = %q, %v", data, err)
+	}
+	if data, err := os.ReadFile(customPath); err != nil || string(data) != "mine" {
+		t.Fatalf("protected file = %q, %v; want mine", data, err)
+	}
+	if _, err := os.Stat(targetDir + ".backups"); !os.IsNotExist(err) {
+		t.Fatalf("backup created with backup.disabled; stat error: %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"oh-my-rime-cli/internal/system"
+)
+
+// TargetCandidates 返回检测到的输入法前端目录；未检测到任何前端时返回当前系统的全部候选目录
+func TargetCandidates() ([]system.TargetCandidate, bool, error) {
+	detected, err := system.DetectTargets()
+	if err != nil {
+		return nil, false, err
+	}
+	if len(detected) > 0 {
+		return detected, true, nil
+	}
+	candidates, err := system.TargetCandidates()
+	return candidates, false, err
+}
+
+// PromptTargetDirs 列出检测到的 Rime 用户目录并让用户选择（可多选），只检测到一个时直接使用
+func PromptTargetDirs(reader *bufio.Reader) ([]string, error) {
+	candidates, detected, err := TargetCandidates()
+	if err != nil {
+		return nil, err
+	}
+	if detected && len(candidates) == 1 {
+		fmt.Printf("检测到 %s（%s）\n", candidates[0].Label, strings.Join(candidates[0].Reasons, "，"))
+		fmt.Println("目标地址: ", candidates[0].Dir)
+		return []string{candidates[0].Dir}, nil
+	}
+	if !detected && len(candidates) > 1 {
+		fmt.Println("\n未检测到已安装的 Rime 输入法前端")
+	}
+
+	fmt.Println("\n==============================")
+	fmt.Println(" 请选择 Rime 配置目录类型 ")
+	fmt.Println("==============================")
+	for i, candidate := range candidates {
+		fmt.Printf("%d. %s", i+1, candidate.Label)
+		if len(candidate.Reasons) > 0 {
+			fmt.Printf("（%s）", strings.Join(candidate.Reasons, "，"))
+		}
+		fmt.Printf("\n   %s\n", candidate.Dir)
+	}
+	fmt.Println("------------------------------")
+	fmt.Println("可输入多个编号同时更新，例如 1,2；a: 全部")
+	fmt.Printf("请输入选项（1-%d，直接回车选择 1）：", len(candidates))
+	choice, _ := reader.ReadString('\n')
+
+	chosen, err := system.ChooseTargets(candidates, choice)
+	if err != nil {
+		return nil, err
+	}
+	var dirs []string
+	for _, candidate := range chosen {
+		fmt.Println("目标地址: ", candidate.Dir)
+		dirs = append(dirs, candidate.Dir)
+	}
+	return dirs, nil
+}
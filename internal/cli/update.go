@@ -0,0 +1,493 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"oh-my-rime-cli/internal/config"
+	"oh-my-rime-cli/internal/deploy"
+	"oh-my-rime-cli/internal/downloader"
+	"oh-my-rime-cli/internal/releases"
+	"oh-my-rime-cli/internal/system"
+	"oh-my-rime-cli/internal/updater"
+)
+
+// hooks 根据命令行参数与配置文件创建钩子配置，命令行参数优先
+func (r *runner) hooks() *updater.Hooks {
+	h := updater.DefaultHooks()
+	if r.flags.HooksDir != "" {
+		h.Dir = system.ExpandHomeDir(r.flags.HooksDir)
+	} else if r.config.Hooks.Dir != "" {
+		h.Dir = system.ExpandHomeDir(r.config.Hooks.Dir)
+	}
+	h.RollbackOnPostFailure = r.flags.HookRollback || r.config.Hooks.Rollback
+	h.Commands = r.config.Hooks.Commands.Stages()
+	return h
+}
+
+// target 一个待更新的 Rime 用户目录及其所属前端
+type target struct {
+	Dir      string
+	Frontend deploy.Frontend
+}
+
+// targets 决定 Rime 用户目录：--target 优先，其次 --frontend 的默认目录，
+// 两者都未指定时使用配置文件中的 targets 与 frontends，最后询问用户（可多选）
+func (r *runner) targets() ([]target, error) {
+	dirs, frontends := []string(r.flags.Targets), []string(r.flags.Frontends)
+	if len(dirs) == 0 && len(frontends) == 0 {
+		dirs, frontends = r.config.Targets, r.config.Frontends
+	}
+
+	if len(dirs) > 0 {
+		if len(frontends) > 0 && len(frontends) != len(dirs) {
+			return nil, fmt.Errorf("配置项 targets 与 frontends 数量必须一致（按顺序对应）")
+		}
+		var targets []target
+		for i, dir := range dirs {
+			dir = system.ExpandHomeDir(dir)
+			frontend := deploy.FrontendForDir(dir)
+			if len(frontends) > 0 {
+				var err error
+				if frontend, err = deploy.ParseFrontend(frontends[i]); err != nil {
+					return nil, err
+				}
+			}
+			targets = append(targets, target{Dir: dir, Frontend: frontend})
+		}
+		return targets, nil
+	}
+	if len(frontends) > 0 {
+		var targets []target
+		for _, name := range frontends {
+			frontend, err := deploy.ParseFrontend(name)
+			if err != nil {
+				return nil, err
+			}
+			dir := deploy.UserDir(frontend)
+			fmt.Println("目标地址: ", dir)
+			targets = append(targets, target{Dir: dir, Frontend: frontend})
+		}
+		return targets, nil
+	}
+	if r.flags.Yes {
+		// 不询问时只有唯一检测到的目录（Windows 总是如此）才能自动决定
+		candidates, detected, err := TargetCandidates()
+		if err != nil {
+			return nil, err
+		}
+		if !detected || len(candidates) != 1 {
+			return nil, fmt.Errorf("%w: --yes 模式下请使用 --target 或 --frontend 指定 Rime 用户目录", errUsage)
+		}
+		return []target{{Dir: candidates[0].Dir, Frontend: deploy.FrontendForDir(candidates[0].Dir)}}, nil
+	}
+	dirs, err := PromptTargetDirs(r.reader)
+	if err != nil {
+		return nil, err
+	}
+	var targets []target
+	for _, dir := range dirs {
+		targets = append(targets, target{Dir: dir, Frontend: deploy.FrontendForDir(dir)})
+	}
+	return targets, nil
+}
+
+// options 返回更新某个目标目录的公共选项
+func (r *runner) options(source, targetDir string) updater.Options {
+	return updater.Options{
+		Source:       source,
+		NameEncoding: r.flags.ZipEncoding,
+		Hooks:        r.hooks(),
+		NoBackup:     r.flags.NoBackup || r.config.Backup.Disabled,
+		BackupKeep:   r.config.Backup.Keep,
+		Protected:    r.config.Protected,
+		Limits:       r.extractLimits(),
+		LockWait:     r.lockWait(),
+		Events:       r.events(targetDir),
+	}
+}
+
+// lockWait 目标目录被锁定时的等待时间：命令行参数优先，其次配置文件
+func (r *runner) lockWait() time.Duration {
+	if r.flags.LockWait != "" {
+		wait, _ := config.ParseLockWait(r.flags.LockWait)
+		return wait
+	}
+	return r.config.LockWaitDuration()
+}
+
+// extractLimits 解压资源限制：命令行参数优先，其次配置文件，最后默认值
+func (r *runner) extractLimits() *updater.ExtractLimits {
+	limits := updater.DefaultExtractLimits
+	r.config.Limits.Apply(&limits)
+	r.flags.limits().Apply(&limits)
+	return &limits
+}
+
+// batch 对多个目标目录执行同一次更新：同一下载地址只下载一次，各目录分别备份、解压并汇总结果，
+// 某个目录失败不会影响其他目录
+type batch struct {
+	r         *runner
+	operation string
+	// asset 版本记录中的资源名，用于查找固定的版本；为空时不支持固定版本
+	asset   string
+	started time.Time
+	targets []target
+	// sources 各目录的下载地址，指定或固定了版本时可能各不相同
+	sources []string
+	errs    []error
+	// pinned 已安装固定版本而跳过更新的目录，值为固定的版本
+	pinned []string
+	// remote 下载前查询到的版本信息，按下载地址记录，记录到目标目录中；查询失败时没有记录
+	remote map[string]*downloader.RemoteInfo
+	// data 下载的内容，按下载地址记录，下载失败时为 nil
+	data map[string][]byte
+	// deployed 已重新部署的前端，多个目录属于同一前端时只部署一次
+	deployed map[deploy.Frontend]bool
+}
+
+// newBatch 选择目标目录并准备本次更新
+func (r *runner) newBatch(operation, asset, source string) (*batch, error) {
+	targets, err := r.targets()
+	if err != nil {
+		return nil, err
+	}
+	return r.newBatchFor(targets, operation, asset, source), nil
+}
+
+// newBatchFor 准备更新已选定的目标目录
+func (r *runner) newBatchFor(targets []target, operation, asset, source string) *batch {
+	r.results = nil
+	sources := make([]string, len(targets))
+	for i := range sources {
+		sources[i] = source
+	}
+	return &batch{
+		r:         r,
+		operation: operation,
+		asset:     asset,
+		started:   time.Now(),
+		targets:   targets,
+		sources:   sources,
+		errs:      make([]error, len(targets)),
+		pinned:    make([]string, len(targets)),
+		remote:    make(map[string]*downloader.RemoteInfo),
+		data:      make(map[string][]byte),
+		deployed:  make(map[deploy.Frontend]bool),
+	}
+}
+
+// pending 返回尚未失败、也未跳过的目标目录的下标
+func (b *batch) pending() []int {
+	var indexes []int
+	for i := range b.targets {
+		if b.errs[i] == nil && b.pinned[i] == "" {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// pendingSources 返回尚未失败的目录使用的下载地址，不重复
+func (b *batch) pendingSources() []string {
+	var sources []string
+	seen := make(map[string]bool)
+	for _, i := range b.pending() {
+		if !seen[b.sources[i]] {
+			seen[b.sources[i]] = true
+			sources = append(sources, b.sources[i])
+		}
+	}
+	return sources
+}
+
+// selectVersions 按 --tag 与各目录固定的版本决定下载地址。已安装固定版本的目录跳过更新，
+// --tag 与固定的版本不一致时该目录报错
+func (b *batch) selectVersions() {
+	for _, i := range b.pending() {
+		dir := b.targets[i].Dir
+		tag := b.r.flags.Tag
+		if b.asset != "" {
+			pins, err := updater.Pins(dir)
+			if err != nil {
+				b.errs[i] = fmt.Errorf("读取固定版本失败: %v", err)
+				continue
+			}
+			if pin := pins[b.asset]; pin != "" {
+				if tag != "" && tag != pin {
+					b.errs[i] = fmt.Errorf("%w: %s 已固定在 %s，请先用 pin 修改或 unpin 解除固定", errUsage, dir, pin)
+					continue
+				}
+				tag = pin
+				if installed, err := updater.InstalledVersions(dir); err == nil && installed[b.asset].Version == pin {
+					b.pinned[i] = pin
+					fmt.Printf("📌 %s 已固定在 %s 且已安装该版本，跳过更新\n", dir, pin)
+					continue
+				}
+			}
+		}
+		if tag == "" {
+			continue
+		}
+		source, err := releases.AssetURL(b.sources[i], tag)
+		if err != nil {
+			b.errs[i] = fmt.Errorf("%w: %v", errUsage, err)
+			continue
+		}
+		b.sources[i] = source
+	}
+}
+
+// beforeDownload 决定各目录的版本并确认发布说明，执行 pre-download 钩子并查询版本信息，
+// 全部被取消或跳过时返回 false
+func (b *batch) beforeDownload() bool {
+	b.selectVersions()
+	if !b.confirmChangelog() {
+		return false
+	}
+	for _, i := range b.pending() {
+		b.errs[i] = BeforeDownload(b.r.hooks(), b.operation, b.targets[i].Dir, b.sources[i])
+	}
+	if len(b.pending()) == 0 {
+		return false
+	}
+	for _, source := range b.pendingSources() {
+		remote, err := downloader.Probe(source)
+		if err != nil {
+			fmt.Printf("获取版本信息失败，将不记录版本: %v\n", err)
+			continue
+		}
+		b.remote[source] = remote
+	}
+	return true
+}
+
+// download 每个下载地址只下载一次，下载失败的目录执行 post-failure 钩子。没有目录可以继续更新时返回 false
+func (b *batch) download(fetch func(source string) []byte, message string) bool {
+	for _, source := range b.pendingSources() {
+		b.data[source] = fetch(source)
+	}
+	for _, i := range b.pending() {
+		if b.data[b.sources[i]] == nil {
+			b.errs[i] = DownloadFailed(b.r.hooks(), b.operation, b.targets[i].Dir, b.sources[i], message)
+		}
+	}
+	return len(b.pending()) > 0
+}
+
+// options 返回更新某个目录的选项，附带下载前查询到的版本信息
+func (b *batch) options(i int) updater.Options {
+	opts := b.r.options(b.sources[i], b.targets[i].Dir)
+	if remote := b.remote[b.sources[i]]; remote != nil {
+		opts.Version = remote.Version
+		opts.ETag = remote.ETag
+		opts.ReleaseDate = remote.LastModified
+	}
+	return opts
+}
+
+// each 依次更新每个目录，记录各自的错误
+func (b *batch) each(update func(t target, data []byte, opts updater.Options) error) {
+	for _, i := range b.pending() {
+		if len(b.targets) > 1 {
+			fmt.Printf("\n[%d/%d] 更新 %s\n", i+1, len(b.targets), b.targets[i].Dir)
+		}
+		b.errs[i] = update(b.targets[i], b.data[b.sources[i]], b.options(i))
+	}
+}
+
+// afterUpdate 按需重新部署并检查部署日志
+func (b *batch) afterUpdate(t target) {
+	if b.deployed[t.Frontend] {
+		return
+	}
+	if Redeploy(b.r.prompter(), t.Frontend, b.r.flags.Deploy) {
+		b.deployed[t.Frontend] = true
+		CheckRimeLogAfterDeploy(t.Frontend, b.started)
+	}
+}
+
+// finish 汇总各目录的结果，只有一个目录失败时原样返回其错误
+func (b *batch) finish() error {
+	var failed []error
+	for i, t := range b.targets {
+		result := newTargetResult(t.Dir, b.errs[i])
+		result.Pinned = b.pinned[i]
+		b.r.results = append(b.r.results, result)
+		if b.errs[i] != nil {
+			failed = append(failed, fmt.Errorf("%s: %w", t.Dir, b.errs[i]))
+		}
+	}
+
+	if len(b.targets) > 1 {
+		fmt.Println("\n更新结果：")
+		for i, t := range b.targets {
+			switch {
+			case b.errs[i] != nil:
+				fmt.Printf("  ❌ %s: %v\n", t.Dir, b.errs[i])
+			case b.pinned[i] != "":
+				fmt.Printf("  📌 %s: 已固定在 %s，未更新\n", t.Dir, b.pinned[i])
+			default:
+				fmt.Printf("  ✅ %s\n", t.Dir)
+			}
+		}
+	}
+
+	switch {
+	case len(failed) == 0:
+		return nil
+	case len(b.targets) == 1:
+		return b.errs[0]
+	}
+	return fmt.Errorf("%d/%d 个目录更新失败: %w", len(failed), len(b.targets), errors.Join(failed...))
+}
+
+// updateScheme 更新主方案
+func (r *runner) updateScheme() error {
+	b, err := r.newBatch(updater.OperationMainScheme, updater.OperationMainScheme, r.config.SchemeURL())
+	if err != nil {
+		return err
+	}
+	if !b.beforeDownload() {
+		return b.finish()
+	}
+	fetch := func(source string) []byte {
+		return downloader.DownloadWithCallback(source, r.progress())
+	}
+	if !b.download(fetch, "下载主方案失败，请检查网络连接或稍后重试") {
+		return b.finish()
+	}
+
+	b.each(func(t target, rimeZip []byte, opts updater.Options) error {
+		if err := updater.UpdateMainSchemeWithOptions(rimeZip, t.Dir, opts); err != nil {
+			return fmt.Errorf("更新主方案失败: %w", err)
+		}
+		b.afterUpdate(t)
+		return nil
+	})
+	return b.finish()
+}
+
+// updateModel 更新模型
+func (r *runner) updateModel() error {
+	targets, err := r.targets()
+	if err != nil {
+		return err
+	}
+	// 多个目录使用同一个模型，以第一个目录的现有模型作为默认选项
+	model := ChooseModel(r.prompter(), targets[0].Dir, r.config.Models())
+	name, err := ModelFileName(r.flags.ModelName, model.FileName)
+	if err != nil {
+		return err
+	}
+
+	b := r.newBatchFor(targets, updater.OperationModel, updater.ModelAsset(name), model.URL)
+	if !b.beforeDownload() {
+		return b.finish()
+	}
+	fetch := func(source string) []byte {
+		return downloader.DownloadWithCallback(source, r.progress())
+	}
+	if !b.download(fetch, "下载模型失败，请检查网络连接或稍后重试") {
+		return b.finish()
+	}
+
+	b.each(func(t target, rimeGram []byte, opts updater.Options) error {
+		opts.ModelName = name
+		if err := updater.UpdateModelWithOptions(rimeGram, t.Dir, opts); err != nil {
+			return fmt.Errorf("更新模型失败: %w", err)
+		}
+		ConfigureGrammar(r.prompter(), t.Dir, name, r.flags.GrammarSchemas)
+		b.afterUpdate(t)
+		return nil
+	})
+	return b.finish()
+}
+
+// updateDict 更新词库
+func (r *runner) updateDict() error {
+	b, err := r.newBatch(updater.OperationDict, updater.OperationDict, r.config.SchemeURL())
+	if err != nil {
+		return err
+	}
+	if !b.beforeDownload() {
+		return b.finish()
+	}
+	fetch := func(source string) []byte {
+		return downloader.DownloadZipEntries(source, updater.IsDictEntry, r.progress())
+	}
+	if !b.download(fetch, "下载词库失败，请检查网络连接或稍后重试") {
+		return b.finish()
+	}
+
+	b.each(func(t target, rimeZip []byte, opts updater.Options) error {
+		selection, err := ChooseDicts(r.prompter(), rimeZip, t.Dir, r.flags.DictInclude, r.flags.DictExclude, r.flags.ZipEncoding)
+		if err != nil {
+			return fmt.Errorf("选择词库失败: %v", err)
+		}
+
+		opts.DictSelection = selection
+		opts.DictSync, _ = updater.ParseDictSyncMode(r.flags.DictSync)
+		if r.flags.DictKeep != "" {
+			opts.DictKeep = SplitGlobs(r.flags.DictKeep)
+		}
+		if err := updater.UpdateDictWithOptions(rimeZip, t.Dir, opts); err != nil {
+			return fmt.Errorf("更新词库失败: %w", err)
+		}
+		b.afterUpdate(t)
+		return nil
+	})
+	return b.finish()
+}
+
+// updateCustom 从 zip（方案）或 gram（模型）地址更新。自定义地址不受固定版本影响
+func (r *runner) updateCustom(customURL string) error {
+	operation, err := updater.CustomOperation(customURL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	isModel := operation == updater.OperationModel
+	customURL = r.config.ResolveURL(customURL)
+
+	b, err := r.newBatch(operation, "", customURL)
+	if err != nil {
+		return err
+	}
+	if !b.beforeDownload() {
+		return b.finish()
+	}
+
+	var fileName string
+	fetch := func(source string) []byte {
+		var data []byte
+		data, fileName = downloader.DownloadFile(source, r.progress())
+		return data
+	}
+	if !b.download(fetch, "下载自定义方案失败，请检查 URL 或网络连接") {
+		return b.finish()
+	}
+
+	b.each(func(t target, customData []byte, opts updater.Options) error {
+		if !isModel {
+			// 如果是 zip 文件，更新主方案
+			if err := updater.UpdateMainSchemeWithOptions(customData, t.Dir, opts); err != nil {
+				return fmt.Errorf("更新自定义方案失败: %w", err)
+			}
+		} else {
+			// 如果是 gram 文件，按下载得到的文件名更新模型，避免覆盖其他模型
+			name, err := ModelFileName(r.flags.ModelName, fileName)
+			if err != nil {
+				return err
+			}
+			opts.ModelName = name
+			if err := updater.UpdateModelWithOptions(customData, t.Dir, opts); err != nil {
+				return fmt.Errorf("更新自定义模型失败: %w", err)
+			}
+			ConfigureGrammar(r.prompter(), t.Dir, name, r.flags.GrammarSchemas)
+		}
+		b.afterUpdate(t)
+		return nil
+	})
+	return b.finish()
+}
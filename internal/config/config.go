@@ -0,0 +1,522 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"oh-my-rime-cli/internal/constants"
+	"oh-my-rime-cli/internal/system"
+	"oh-my-rime-cli/internal/updater"
+)
+
+// FileName 配置文件名，位于 system.AppConfigDir 下
+const FileName = "config.yaml"
+
+// EnvPrefix 环境变量前缀，配置项 backup.keep 对应 OH_MY_RIME_BACKUP_KEEP
+const EnvPrefix = "OH_MY_RIME_"
+
+// ErrUnknownKey 配置项不存在
+var ErrUnknownKey = errors.New("未知的配置项")
+
+// Config 命令行与图形界面共用的配置
+type Config struct {
+	// Targets 默认更新的 Rime 用户目录，设置后不再询问
+	Targets []string `yaml:"targets,omitempty"`
+	// Frontends 默认使用的输入法前端，未设置 Targets 时使用其默认目录
+	Frontends []string `yaml:"frontends,omitempty"`
+	Sources   Sources  `yaml:"sources,omitempty"`
+	// Protected 目标目录中已存在时不会被更新覆盖的文件，如 *.custom.yaml
+	Protected []string `yaml:"protected,omitempty"`
+	Backup    Backup   `yaml:"backup,omitempty"`
+	// Limits 解压资源限制，防止异常的更新包耗尽磁盘
+	Limits Limits `yaml:"limits,omitempty"`
+	// LockWait 目标目录正被其他进程更新时的最长等待时间，如 30s、2m；为空时立即报错
+	LockWait string `yaml:"lock_wait,omitempty"`
+	// Proxy 下载使用的代理，为空时遵循 HTTP_PROXY、HTTPS_PROXY 环境变量
+	Proxy string `yaml:"proxy,omitempty"`
+	Hooks Hooks  `yaml:"hooks,omitempty"`
+}
+
+// Sources 下载地址
+type Sources struct {
+	// Scheme 薄荷方案（含词库）zip 的地址
+	Scheme string `yaml:"scheme,omitempty"`
+	// Model 万象简体模型的地址
+	Model string `yaml:"model,omitempty"`
+	// ModelHant 万象繁体模型的地址
+	ModelHant string `yaml:"model_hant,omitempty"`
+	// Mirrors 下载前按前缀替换地址，如把 https://github.com/ 换成镜像站
+	Mirrors []Mirror `yaml:"mirrors,omitempty"`
+}
+
+// Mirror 地址前缀替换规则
+type Mirror struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+}
+
+// Backup 备份策略
+type Backup struct {
+	// Keep 每个目录保留的备份数量，0 时使用默认值
+	Keep int `yaml:"keep,omitempty"`
+	// Disabled 更新前不创建备份
+	Disabled bool `yaml:"disabled,omitempty"`
+}
+
+// Hooks 钩子设置
+type Hooks struct {
+	// Dir 钩子脚本目录，为空时使用默认目录
+	Dir string `yaml:"dir,omitempty"`
+	// Rollback post-extract 钩子失败时恢复备份
+	Rollback bool `yaml:"rollback,omitempty"`
+	// Commands 各阶段通过 shell 执行的命令，在钩子目录中的脚本之后执行
+	Commands HookCommands `yaml:"commands,omitempty"`
+}
+
+// HookCommands 各阶段的钩子命令，每项为一条 shell 命令
+type HookCommands struct {
+	PreDownload []string `yaml:"pre_download,omitempty"`
+	PreExtract  []string `yaml:"pre_extract,omitempty"`
+	PostExtract []string `yaml:"post_extract,omitempty"`
+	PostFailure []string `yaml:"post_failure,omitempty"`
+}
+
+// Stages 按钩子阶段返回命令，用于 updater.Hooks.Commands
+func (h HookCommands) Stages() map[updater.HookStage][]string {
+	return map[updater.HookStage][]string{
+		updater.HookPreDownload: h.PreDownload,
+		updater.HookPreExtract:  h.PreExtract,
+		updater.HookPostExtract: h.PostExtract,
+		updater.HookPostFailure: h.PostFailure,
+	}
+}
+
+// Path 返回配置文件路径
+func Path() string {
+	return filepath.Join(system.AppConfigDir(), FileName)
+}
+
+// Load 读取配置文件并应用环境变量覆盖，文件不存在时返回空配置
+func Load() (*Config, error) {
+	c, err := ReadFile(Path())
+	if err != nil {
+		return nil, err
+	}
+	if err := c.ApplyEnv(os.Getenv); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ReadFile 读取配置文件，文件不存在时返回空配置。未知的配置项视为错误，避免拼写错误被忽略
+func ReadFile(path string) (*Config, error) {
+	c := &Config{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return nil, fmt.Errorf("读取配置文件失败: %v", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("解析配置文件 %s 失败: %v", path, err)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("配置文件 %s 无效: %v", path, err)
+	}
+	return c, nil
+}
+
+// Save 写入配置文件
+func (c *Config) Save(path string) error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建配置目录失败: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("写入配置文件失败: %v", err)
+	}
+	return nil
+}
+
+// EnsureFile 配置文件不存在时写入 Template，便于用户在此基础上修改
+func EnsureFile(path string) error {
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建配置目录失败: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(Template), 0644); err != nil {
+		return fmt.Errorf("创建配置文件失败: %v", err)
+	}
+	return nil
+}
+
+// Validate 检查配置取值
+func (c *Config) Validate() error {
+	if c.Backup.Keep < 0 {
+		return fmt.Errorf("backup.keep 不能为负数")
+	}
+	for _, mirror := range c.Sources.Mirrors {
+		if mirror.From == "" || mirror.To == "" {
+			return fmt.Errorf("sources.mirrors 的 from 和 to 不能为空")
+		}
+	}
+	if err := c.Limits.Validate(); err != nil {
+		return fmt.Errorf("limits: %v", err)
+	}
+	if _, err := ParseLockWait(c.LockWait); err != nil {
+		return fmt.Errorf("lock_wait: %v", err)
+	}
+	return nil
+}
+
+// ParseLockWait 解析锁等待时间，如 30s、2m，空值表示不等待
+func ParseLockWait(value string) (time.Duration, error) {
+	if strings.TrimSpace(value) == "" {
+		return 0, nil
+	}
+	wait, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil || wait < 0 {
+		return 0, fmt.Errorf("无效的等待时间: %s（如 30s、2m）", value)
+	}
+	return wait, nil
+}
+
+// LockWaitDuration 返回锁等待时间，取值应已通过 Validate
+func (c *Config) LockWaitDuration() time.Duration {
+	wait, _ := ParseLockWait(c.LockWait)
+	return wait
+}
+
+// ApplyEnv 用环境变量覆盖配置项，变量名为 EnvPrefix 加上大写的配置项名，点号换成下划线
+func (c *Config) ApplyEnv(getenv func(string) string) error {
+	for _, key := range Keys() {
+		value := getenv(EnvName(key))
+		if value == "" {
+			continue
+		}
+		if err := c.Set(key, value); err != nil {
+			return fmt.Errorf("环境变量 %s 无效: %v", EnvName(key), err)
+		}
+	}
+	return nil
+}
+
+// EnvName 返回覆盖配置项的环境变量名
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// field 一个可通过 config get/set 读写的配置项
+type field struct {
+	key string
+	get func(c *Config) string
+	set func(c *Config, value string) error
+}
+
+func listField(key string, list func(c *Config) *[]string) field {
+	return field{
+		key: key,
+		get: func(c *Config) string { return strings.Join(*list(c), ",") },
+		set: func(c *Config, value string) error {
+			*list(c) = splitList(value)
+			return nil
+		},
+	}
+}
+
+// commandsField 命令列表配置项，命令中可能含有逗号，因此每行一条命令
+func commandsField(key string, list func(c *Config) *[]string) field {
+	return field{
+		key: key,
+		get: func(c *Config) string { return strings.Join(*list(c), "\n") },
+		set: func(c *Config, value string) error {
+			var commands []string
+			for _, line := range strings.Split(value, "\n") {
+				if line = strings.TrimSpace(line); line != "" {
+					commands = append(commands, line)
+				}
+			}
+			*list(c) = commands
+			return nil
+		},
+	}
+}
+
+func stringField(key string, str func(c *Config) *string) field {
+	return field{
+		key: key,
+		get: func(c *Config) string { return *str(c) },
+		set: func(c *Config, value string) error {
+			*str(c) = strings.TrimSpace(value)
+			return nil
+		},
+	}
+}
+
+func boolField(key string, b func(c *Config) *bool) field {
+	return field{
+		key: key,
+		get: func(c *Config) string { return strconv.FormatBool(*b(c)) },
+		set: func(c *Config, value string) error {
+			parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+			if err != nil {
+				return fmt.Errorf("需要 true 或 false: %s", value)
+			}
+			*b(c) = parsed
+			return nil
+		},
+	}
+}
+
+// sizeField 大小配置项，如 512MB，空值清除
+func sizeField(key string, str func(c *Config) *string) field {
+	return field{
+		key: key,
+		get: func(c *Config) string { return *str(c) },
+		set: func(c *Config, value string) error {
+			value = strings.TrimSpace(value)
+			if value != "" {
+				if _, err := ParseSize(value); err != nil {
+					return err
+				}
+			}
+			*str(c) = value
+			return nil
+		},
+	}
+}
+
+var fields = []field{
+	listField("targets", func(c *Config) *[]string { return &c.Targets }),
+	listField("frontends", func(c *Config) *[]string { return &c.Frontends }),
+	stringField("sources.scheme", func(c *Config) *string { return &c.Sources.Scheme }),
+	stringField("sources.model", func(c *Config) *string { return &c.Sources.Model }),
+	stringField("sources.model_hant", func(c *Config) *string { return &c.Sources.ModelHant }),
+	{
+		key: "sources.mirrors",
+		get: func(c *Config) string {
+			var rules []string
+			for _, mirror := range c.Sources.Mirrors {
+				rules = append(rules, mirror.From+"="+mirror.To)
+			}
+			return strings.Join(rules, ",")
+		},
+		set: func(c *Config, value string) error {
+			var mirrors []Mirror
+			for _, rule := range splitList(value) {
+				from, to, ok := strings.Cut(rule, "=")
+				if !ok || from == "" || to == "" {
+					return fmt.Errorf("镜像规则应为 原地址前缀=镜像地址前缀: %s", rule)
+				}
+				mirrors = append(mirrors, Mirror{From: from, To: to})
+			}
+			c.Sources.Mirrors = mirrors
+			return nil
+		},
+	},
+	listField("protected", func(c *Config) *[]string { return &c.Protected }),
+	{
+		key: "backup.keep",
+		get: func(c *Config) string { return strconv.Itoa(c.Backup.Keep) },
+		set: func(c *Config, value string) error {
+			keep, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || keep < 0 {
+				return fmt.Errorf("需要非负整数: %s", value)
+			}
+			c.Backup.Keep = keep
+			return nil
+		},
+	},
+	boolField("backup.disabled", func(c *Config) *bool { return &c.Backup.Disabled }),
+	sizeField("limits.max_total_size", func(c *Config) *string { return &c.Limits.MaxTotalSize }),
+	sizeField("limits.max_file_size", func(c *Config) *string { return &c.Limits.MaxFileSize }),
+	{
+		key: "limits.max_entries",
+		get: func(c *Config) string { return strconv.Itoa(c.Limits.MaxEntries) },
+		set: func(c *Config, value string) error {
+			entries, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || entries < 0 {
+				return fmt.Errorf("需要非负整数: %s", value)
+			}
+			c.Limits.MaxEntries = entries
+			return nil
+		},
+	},
+	{
+		key: "limits.max_compression_ratio",
+		get: func(c *Config) string { return strconv.FormatFloat(c.Limits.MaxCompressionRatio, 'g', -1, 64) },
+		set: func(c *Config, value string) error {
+			ratio, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || ratio < 0 {
+				return fmt.Errorf("需要非负数: %s", value)
+			}
+			c.Limits.MaxCompressionRatio = ratio
+			return nil
+		},
+	},
+	{
+		key: "lock_wait",
+		get: func(c *Config) string { return c.LockWait },
+		set: func(c *Config, value string) error {
+			if _, err := ParseLockWait(value); err != nil {
+				return err
+			}
+			c.LockWait = strings.TrimSpace(value)
+			return nil
+		},
+	},
+	stringField("proxy", func(c *Config) *string { return &c.Proxy }),
+	stringField("hooks.dir", func(c *Config) *string { return &c.Hooks.Dir }),
+	boolField("hooks.rollback", func(c *Config) *bool { return &c.Hooks.Rollback }),
+	commandsField("hooks.commands.pre_download", func(c *Config) *[]string { return &c.Hooks.Commands.PreDownload }),
+	commandsField("hooks.commands.pre_extract", func(c *Config) *[]string { return &c.Hooks.Commands.PreExtract }),
+	commandsField("hooks.commands.post_extract", func(c *Config) *[]string { return &c.Hooks.Commands.PostExtract }),
+	commandsField("hooks.commands.post_failure", func(c *Config) *[]string { return &c.Hooks.Commands.PostFailure }),
+}
+
+// Keys 返回全部配置项名
+func Keys() []string {
+	keys := make([]string, len(fields))
+	for i, f := range fields {
+		keys[i] = f.key
+	}
+	return keys
+}
+
+func findField(key string) (field, error) {
+	for _, f := range fields {
+		if f.key == key {
+			return f, nil
+		}
+	}
+	return field{}, fmt.Errorf("%w: %s（可选 %s）", ErrUnknownKey, key, strings.Join(Keys(), "、"))
+}
+
+// Get 返回配置项的值，列表以逗号分隔
+func (c *Config) Get(key string) (string, error) {
+	f, err := findField(key)
+	if err != nil {
+		return "", err
+	}
+	return f.get(c), nil
+}
+
+// Set 设置配置项，列表以逗号分隔，空值清除该项
+func (c *Config) Set(key, value string) error {
+	f, err := findField(key)
+	if err != nil {
+		return err
+	}
+	return f.set(c, value)
+}
+
+// splitList 拆分逗号分隔的列表，忽略空项
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ResolveURL 按镜像规则替换下载地址
+func (c *Config) ResolveURL(url string) string {
+	for _, mirror := range c.Sources.Mirrors {
+		if strings.HasPrefix(url, mirror.From) {
+			return mirror.To + strings.TrimPrefix(url, mirror.From)
+		}
+	}
+	return url
+}
+
+// SchemeURL 返回薄荷方案的下载地址（已应用镜像规则）
+func (c *Config) SchemeURL() string {
+	if c.Sources.Scheme != "" {
+		return c.ResolveURL(c.Sources.Scheme)
+	}
+	return c.ResolveURL(constants.OhMyRimeRepo)
+}
+
+// Models 返回可下载的万象模型，下载地址已应用配置中的覆盖与镜像规则
+func (c *Config) Models() []constants.WanXiangModel {
+	overrides := map[string]string{
+		constants.WanXiangModels[0].FileName: c.Sources.Model,
+		constants.WanXiangModels[1].FileName: c.Sources.ModelHant,
+	}
+	models := make([]constants.WanXiangModel, len(constants.WanXiangModels))
+	for i, model := range constants.WanXiangModels {
+		if url := overrides[model.FileName]; url != "" {
+			model.URL = url
+		}
+		model.URL = c.ResolveURL(model.URL)
+		models[i] = model
+	}
+	return models
+}
+
+// Template 新建配置文件时写入的示例，全部为注释，不改变默认行为
+const Template = `# Oh My Rime 配置文件，命令行与图形界面共用
+# 每一项都可以用环境变量覆盖，如 OH_MY_RIME_PROXY、OH_MY_RIME_BACKUP_KEEP；命令行参数优先于环境变量
+
+# 默认更新的 Rime 用户目录，设置后不再询问
+# targets:
+#   - ~/.local/share/fcitx5/rime
+# 未设置 targets 时使用这些前端的默认目录（ibus、fcitx5、fcitx5-flatpak、fcitx、squirrel、weasel）
+# frontends: [fcitx5]
+
+# sources:
+#   scheme: https://cnb.cool/Mintimate/rime/oh-my-rime/-/releases/download/latest/oh-my-rime.zip
+#   model: https://cnb.cool/Mintimate/rime/oh-my-rime/-/releases/download/latest/wanxiang-lts-zh-hans.gram
+#   model_hant: https://cnb.cool/Mintimate/rime/oh-my-rime/-/releases/download/latest/wanxiang-lts-zh-hant.gram
+#   mirrors:
+#     - from: https://github.com/
+#       to: https://ghfast.top/https://github.com/
+
+# 已存在时不会被更新覆盖的文件
+# protected:
+#   - "*.custom.yaml"
+
+# backup:
+#   keep: 3
+#   disabled: false
+
+# 解压资源限制，超出时拒绝更新包；未设置的项使用默认值
+# limits:
+#   max_total_size: 2GB
+#   max_file_size: 1GB
+#   max_entries: 20000
+#   max_compression_ratio: 200
+
+# 目标目录正被其他进程更新时最多等待多久，为空时立即报错
+# lock_wait: 30s
+
+# proxy: http://127.0.0.1:7890
+
+# hooks:
+#   dir: ~/.config/oh-my-rime/hooks
+#   rollback: false
+#   # 各阶段额外执行的 shell 命令，在钩子目录中的脚本之后执行
+#   commands:
+#     post_extract:
+#       - echo "更新完成: $OH_MY_RIME_TARGET_DIR"
+`
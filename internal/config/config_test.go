@@ -0,0 +1,212 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"oh-my-rime-cli/internal/constants"
+	"oh-my-rime-cli/internal/updater"
+)
+
+func TestReadFileMissingReturnsEmptyConfig(t *testing.T) {
+	c, err := ReadFile(filepath.Join(t.TempDir(), FileName))
+	if err != nil {
+		t.Fatalf("ReadFile returned error: %v", err)
+	}
+	if !reflect.DeepEqual(c, &Config{}) {
+		t.Fatalf("config = %+v; want empty", c)
+	}
+}
+
+func TestSaveAndReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "oh-my-rime", FileName)
+	want := &Config{
+		Targets:   []string{"~/.config/ibus/rime", "~/.local/share/fcitx5/rime"},
+		Sources:   Sources{Mirrors: []Mirror{{From: "https://github.com/", To: "https://mirror.example/github/"}}},
+		Protected: []string{"*.custom.yaml"},
+		Backup:    Backup{Keep: 5},
+		Proxy:     "http://127.0.0.1:7890",
+		Hooks:     Hooks{Rollback: true},
+	}
+	if err := want.Save(path); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+	got, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile returned error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("config = %+v; want %+v", got, want)
+	}
+}
+
+func TestReadFileRejectsInvalidConfig(t *testing.T) {
+	tests := map[string]string{
+		"unknown key":   "backups:\n  keep: 3\n",
+		"negative keep": "backup:\n  keep: -1\n",
+		"empty mirror":  "sources:\n  mirrors:\n    - from: https://github.com/\n",
+	}
+	for name, content := range tests {
+		path := filepath.Join(t.TempDir(), FileName)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ReadFile(path); err == nil {
+			t.Errorf("%s: ReadFile returned nil; want error", name)
+		}
+	}
+}
+
+func TestTemplateIsValidConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte(Template), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if c, err := ReadFile(path); err != nil || !reflect.DeepEqual(c, &Config{}) {
+		t.Fatalf("ReadFile(template) = %+v, %v; want empty config", c, err)
+	}
+}
+
+func TestGetSetAndEnv(t *testing.T) {
+	c := &Config{}
+	if err := c.Set("sources.mirrors", "https://github.com/=https://mirror.example/gh/"); err != nil {
+		t.Fatalf("Set returned error: %v", err)
+	}
+	if err := c.Set("backup.keep", "many"); err == nil {
+		t.Error("Set(backup.keep, many) returned nil; want error")
+	}
+	if err := c.Set("no.such.key", "1"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Set(no.such.key) = %v; want ErrUnknownKey", err)
+	}
+
+	env := map[string]string{
+		"OH_MY_RIME_BACKUP_KEEP": "7",
+		"OH_MY_RIME_TARGETS":     "/a, /b",
+		"OH_MY_RIME_PROXY":       "socks5://127.0.0.1:1080",
+	}
+	if err := c.ApplyEnv(func(key string) string { return env[key] }); err != nil {
+		t.Fatalf("ApplyEnv returned error: %v", err)
+	}
+	for key, want := range map[string]string{
+		"backup.keep":     "7",
+		"targets":         "/a,/b",
+		"proxy":           "socks5://127.0.0.1:1080",
+		"sources.mirrors": "https://github.com/=https://mirror.example/gh/",
+		"hooks.rollback":  "false",
+	} {
+		if got, err := c.Get(key); err != nil || got != want {
+			t.Errorf("Get(%s) = %q, %v; want %q", key, got, err, want)
+		}
+	}
+
+	if err := c.Set("lock_wait", "soon"); err == nil {
+		t.Error("Set(lock_wait, soon) returned nil; want error")
+	}
+	if err := c.Set("lock_wait", "2m"); err != nil || c.LockWaitDuration() != 2*time.Minute {
+		t.Errorf("Set(lock_wait, 2m) = %v, LockWaitDuration() = %v; want 2m", err, c.LockWaitDuration())
+	}
+
+	env["OH_MY_RIME_BACKUP_DISABLED"] = "maybe"
+	if err := c.ApplyEnv(func(key string) string { return env[key] }); err == nil {
+		t.Error("ApplyEnv with invalid bool returned nil; want error")
+	}
+}
+
+func TestHookCommands(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	content := "hooks:\n  commands:\n    post_extract:\n      - echo a, b\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile returned error: %v", err)
+	}
+	env := map[string]string{"OH_MY_RIME_HOOKS_COMMANDS_PRE_DOWNLOAD": "test -d /\n\ntrue"}
+	if err := c.ApplyEnv(func(key string) string { return env[key] }); err != nil {
+		t.Fatalf("ApplyEnv returned error: %v", err)
+	}
+
+	stages := c.Hooks.Commands.Stages()
+	if got := stages[updater.HookPostExtract]; !reflect.DeepEqual(got, []string{"echo a, b"}) {
+		t.Errorf("post-extract commands = %q; want [echo a, b]", got)
+	}
+	if got := stages[updater.HookPreDownload]; !reflect.DeepEqual(got, []string{"test -d /", "true"}) {
+		t.Errorf("pre-download commands = %q; want [test -d / true]", got)
+	}
+
+	if err := os.WriteFile(path, []byte("hooks:\n  commands:\n    post_install: [true]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadFile(path); err == nil {
+		t.Error("ReadFile with unknown hook stage returned nil; want error")
+	}
+}
+
+func TestLimits(t *testing.T) {
+	for value, want := range map[string]int64{"1048576": 1 << 20, "512MB": 512 << 20, "1.5 g": 3 << 29, "2GB": 2 << 30} {
+		if got, err := ParseSize(value); err != nil || got != want {
+			t.Errorf("ParseSize(%q) = %d, %v; want %d", value, got, err, want)
+		}
+	}
+	c := &Config{}
+	if err := c.Set("limits.max_total_size", "lots"); err == nil {
+		t.Error("Set(limits.max_total_size, lots) returned nil; want error")
+	}
+	env := map[string]string{"OH_MY_RIME_LIMITS_MAX_FILE_SIZE": "64MB", "OH_MY_RIME_LIMITS_MAX_ENTRIES": "10"}
+	if err := c.ApplyEnv(func(key string) string { return env[key] }); err != nil {
+		t.Fatalf("ApplyEnv returned error: %v", err)
+	}
+
+	limits := updater.DefaultExtractLimits
+	c.Limits.Apply(&limits)
+	want := updater.DefaultExtractLimits
+	want.MaxFileSize, want.MaxEntries = 64<<20, 10
+	if limits != want {
+		t.Errorf("Apply = %+v; want %+v", limits, want)
+	}
+}
+
+func TestDownloadURLs(t *testing.T) {
+	c := &Config{Sources: Sources{
+		ModelHant: "https://github.com/example/hant.gram",
+		Mirrors:   []Mirror{{From: "https://github.com/", To: "https://mirror.example/gh/"}},
+	}}
+	if got := c.SchemeURL(); got != constants.OhMyRimeRepo {
+		t.Errorf("SchemeURL() = %s; want default", got)
+	}
+	models := c.Models()
+	if models[0].URL != constants.WanXiangGRA {
+		t.Errorf("models[0].URL = %s; want default", models[0].URL)
+	}
+	if want := "https://mirror.example/gh/example/hant.gram"; models[1].URL != want {
+		t.Errorf("models[1].URL = %s; want %s", models[1].URL, want)
+	}
+	if constants.WanXiangModels[1].URL != constants.WanXiangGRAHant {
+		t.Error("Models() modified constants.WanXiangModels")
+	}
+}
+
+func TestEnsureFileKeepsExistingConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "oh-my-rime", FileName)
+	if err := EnsureFile(path); err != nil {
+		t.Fatalf("EnsureFile returned error: %v", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != Template {
+		t.Fatalf("new config = %q, %v; want template", data, err)
+	}
+
+	if err := os.WriteFile(path, []byte("proxy: http://127.0.0.1:7890\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := EnsureFile(path); err != nil {
+		t.Fatalf("EnsureFile returned error: %v", err)
+	}
+	if c, err := ReadFile(path); err != nil || c.Proxy != "http://127.0.0.1:7890" {
+		t.Fatalf("config after EnsureFile = %+v, %v", c, err)
+	}
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"oh-my-rime-cli/internal/updater"
+)
+
+// Limits 解压资源限制，未设置的项使用 updater.DefaultExtractLimits
+type Limits struct {
+	// MaxTotalSize、MaxFileSize 解压后的总大小与单个文件大小，如 2GB、512MB
+	MaxTotalSize string `yaml:"max_total_size,omitempty"`
+	MaxFileSize  string `yaml:"max_file_size,omitempty"`
+	// MaxEntries zip 条目数
+	MaxEntries int `yaml:"max_entries,omitempty"`
+	// MaxCompressionRatio 单个文件的最大压缩比
+	MaxCompressionRatio float64 `yaml:"max_compression_ratio,omitempty"`
+}
+
+// 大小单位，与 downloader.FormatBytes 一致按 1024 进位
+var sizeUnits = []struct {
+	suffix string
+	size   float64
+}{
+	{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30}, {"TB", 1 << 40},
+	{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}, {"T", 1 << 40},
+	{"B", 1},
+}
+
+// ParseSize 解析大小，如 512MB、1.5G、1048576（字节）
+func ParseSize(value string) (int64, error) {
+	text := strings.ToUpper(strings.TrimSpace(value))
+	multiplier := 1.0
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(text, unit.suffix) {
+			text, multiplier = strings.TrimSpace(strings.TrimSuffix(text, unit.suffix)), unit.size
+			break
+		}
+	}
+	number, err := strconv.ParseFloat(text, 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("无效的大小: %s（如 512MB、2GB）", value)
+	}
+	return int64(number * multiplier), nil
+}
+
+// Validate 检查限制的取值
+func (l Limits) Validate() error {
+	for _, size := range []string{l.MaxTotalSize, l.MaxFileSize} {
+		if size == "" {
+			continue
+		}
+		if _, err := ParseSize(size); err != nil {
+			return err
+		}
+	}
+	if l.MaxEntries < 0 {
+		return fmt.Errorf("max_entries 不能为负数")
+	}
+	if l.MaxCompressionRatio < 0 {
+		return fmt.Errorf("max_compression_ratio 不能为负数")
+	}
+	return nil
+}
+
+// Apply 用已设置的项覆盖 limits，取值应已通过 Validate
+func (l Limits) Apply(limits *updater.ExtractLimits) {
+	if size, err := ParseSize(l.MaxTotalSize); l.MaxTotalSize != "" && err == nil {
+		limits.MaxTotalSize = size
+	}
+	if size, err := ParseSize(l.MaxFileSize); l.MaxFileSize != "" && err == nil {
+		limits.MaxFileSize = size
+	}
+	if l.MaxEntries > 0 {
+		limits.MaxEntries = l.MaxEntries
+	}
+	if l.MaxCompressionRatio > 0 {
+		limits.MaxCompressionRatio = l.MaxCompressionRatio
+	}
+}
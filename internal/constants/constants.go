@@ -21,4 +21,22 @@ const (
 	OhMyRimeRepo = "https://cnb.cool/Mintimate/rime/oh-my-rime/-/releases/download/latest/oh-my-rime.zip"
 	// 万象模型镜像
 	WanXiangGRA = "https://cnb.cool/Mintimate/rime/oh-my-rime/-/releases/download/latest/wanxiang-lts-zh-hans.gram"
+	// 万象繁体模型镜像
+	WanXiangGRAHant = "https://cnb.cool/Mintimate/rime/oh-my-rime/-/releases/download/latest/wanxiang-lts-zh-hant.gram"
 )
+
+// WanXiangModel 万象语法模型的一个版本
+type WanXiangModel struct {
+	// Name 显示名称
+	Name string
+	// FileName 安装到 Rime 配置目录中的文件名，需与方案中 grammar/language 的设置对应
+	FileName string
+	// URL 下载地址
+	URL string
+}
+
+// WanXiangModels 已知的万象模型版本，第一项为默认模型
+var WanXiangModels = []WanXiangModel{
+	{Name: "万象模型（简体）", FileName: "wanxiang-lts-zh-hans.gram", URL: WanXiangGRA},
+	{Name: "万象模型（繁体）", FileName: "wanxiang-lts-zh-hant.gram", URL: WanXiangGRAHant},
+}
@@ -0,0 +1,193 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"oh-my-rime-cli/internal/system"
+)
+
+// Frontend Rime 输入法前端
+type Frontend string
+
+const (
+	FrontendUnknown       Frontend = ""
+	FrontendIBus          Frontend = "ibus"
+	FrontendFcitx5        Frontend = "fcitx5"
+	FrontendFcitx5Flatpak Frontend = "fcitx5-flatpak"
+	FrontendFcitx         Frontend = "fcitx"
+	FrontendSquirrel      Frontend = "squirrel"
+	FrontendWeasel        Frontend = "weasel"
+)
+
+// Frontends 支持的全部前端
+var Frontends = []Frontend{FrontendIBus, FrontendFcitx5, FrontendFcitx5Flatpak, FrontendFcitx, FrontendSquirrel, FrontendWeasel}
+
+// ErrNoDeployCommand 没有可用的部署命令
+var ErrNoDeployCommand = errors.New("未找到可用的部署命令")
+
+// 部署命令的最长执行时间
+const deployTimeout = 30 * time.Second
+
+// ParseFrontend 解析用户输入的前端名称
+func ParseFrontend(name string) (Frontend, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, frontend := range Frontends {
+		if string(frontend) == name {
+			return frontend, nil
+		}
+	}
+	names := make([]string, 0, len(Frontends))
+	for _, frontend := range Frontends {
+		names = append(names, string(frontend))
+	}
+	return FrontendUnknown, fmt.Errorf("不支持的前端: %s（可选 %s）", name, strings.Join(names, "、"))
+}
+
+// FrontendForDir 根据 Rime 用户目录推断所属前端，无法判断时返回 FrontendUnknown
+func FrontendForDir(targetDir string) Frontend {
+	dir := filepath.ToSlash(filepath.Clean(targetDir))
+	switch {
+	case strings.Contains(dir, "/.var/app/org.fcitx.Fcitx5/"):
+		return FrontendFcitx5Flatpak
+	case strings.HasSuffix(dir, "/fcitx5/rime"):
+		return FrontendFcitx5
+	case strings.HasSuffix(dir, "/fcitx/rime"):
+		return FrontendFcitx
+	case strings.HasSuffix(dir, "/ibus/rime"):
+		return FrontendIBus
+	case strings.HasSuffix(dir, "/Library/Rime"):
+		return FrontendSquirrel
+	case runtime.GOOS == "windows":
+		return FrontendWeasel
+	}
+	return FrontendUnknown
+}
+
+// UserDir 返回前端默认的 Rime 用户目录，小狼毫优先读取注册表中的设置
+func UserDir(frontend Frontend) string {
+	return system.RimeUserDir(string(frontend))
+}
+
+// Command 一条部署命令
+type Command struct {
+	Name string
+	Args []string
+}
+
+func (c Command) String() string {
+	return strings.TrimSpace(c.Name + " " + strings.Join(c.Args, " "))
+}
+
+// Commands 返回前端的候选部署命令，按优先顺序排列
+func Commands(frontend Frontend) []Command {
+	switch frontend {
+	case FrontendIBus:
+		return []Command{
+			{Name: "ibus-daemon", Args: []string{"-rdx"}},
+			{Name: "ibus", Args: []string{"restart"}},
+		}
+	case FrontendFcitx5:
+		return []Command{
+			{Name: "fcitx5-remote", Args: []string{"-r"}},
+			{Name: "dbus-send", Args: []string{"--session", "--type=method_call", "--dest=org.fcitx.Fcitx5",
+				"/controller", "org.fcitx.Fcitx.Controller1.ReloadAddonConfig", "string:rime"}},
+		}
+	case FrontendFcitx5Flatpak:
+		return []Command{
+			{Name: "flatpak", Args: []string{"run", "--command=fcitx5-remote", "org.fcitx.Fcitx5", "-r"}},
+		}
+	case FrontendFcitx:
+		return []Command{
+			{Name: "fcitx-remote", Args: []string{"-r"}},
+		}
+	case FrontendSquirrel:
+		return []Command{
+			{Name: "/Library/Input Methods/Squirrel.app/Contents/MacOS/Squirrel", Args: []string{"--reload"}},
+			{Name: "Squirrel", Args: []string{"--reload"}},
+		}
+	case FrontendWeasel:
+		var commands []Command
+		for _, path := range weaselDeployers() {
+			commands = append(commands, Command{Name: path, Args: []string{"/deploy"}})
+		}
+		return append(commands, Command{Name: "WeaselDeployer.exe", Args: []string{"/deploy"}})
+	}
+	return nil
+}
+
+// weaselDeployers 在默认安装目录中查找小狼毫部署程序，新版本优先
+func weaselDeployers() []string {
+	var paths []string
+	for _, env := range []string{"ProgramFiles", "ProgramFiles(x86)"} {
+		if root := os.Getenv(env); root != "" {
+			matches, _ := filepath.Glob(filepath.Join(root, "Rime", "weasel-*", "WeaselDeployer.exe"))
+			sort.Sort(sort.Reverse(sort.StringSlice(matches)))
+			paths = append(paths, matches...)
+		}
+	}
+	return paths
+}
+
+// Deployer 执行部署命令，LookPath 与 Run 可替换以便测试
+type Deployer struct {
+	// LookPath 解析可执行文件路径，默认 exec.LookPath
+	LookPath func(file string) (string, error)
+	// Run 执行命令并返回输出，默认在 deployTimeout 内运行
+	Run func(path string, args []string) ([]byte, error)
+}
+
+// New 返回使用系统命令的 Deployer
+func New() *Deployer {
+	return &Deployer{LookPath: exec.LookPath, Run: runCommand}
+}
+
+func runCommand(path string, args []string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), deployTimeout)
+	defer cancel()
+	return exec.CommandContext(ctx, path, args...).CombinedOutput()
+}
+
+// Deploy 依次尝试前端的部署命令，返回执行成功的命令
+func (d *Deployer) Deploy(frontend Frontend) (Command, error) {
+	commands := Commands(frontend)
+	if len(commands) == 0 {
+		return Command{}, fmt.Errorf("%w: 无法识别输入法前端", ErrNoDeployCommand)
+	}
+
+	var failures []string
+	for _, command := range commands {
+		path, err := d.LookPath(command.Name)
+		if err != nil {
+			continue
+		}
+		output, err := d.Run(path, command.Args)
+		if err != nil {
+			failure := fmt.Sprintf("%s: %v", command, err)
+			if text := strings.TrimSpace(string(output)); text != "" {
+				failure += " (" + text + ")"
+			}
+			failures = append(failures, failure)
+			continue
+		}
+		return command, nil
+	}
+
+	if len(failures) > 0 {
+		return Command{}, fmt.Errorf("部署 %s 失败: %s", frontend, strings.Join(failures, "; "))
+	}
+	return Command{}, fmt.Errorf("%w: %s", ErrNoDeployCommand, frontend)
+}
+
+// Deploy 使用系统命令重新部署指定前端
+func Deploy(frontend Frontend) (Command, error) {
+	return New().Deploy(frontend)
+}
@@ -0,0 +1,156 @@
+package deploy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// writeStub 在 dir 中创建记录参数并以 exitCode 退出的脚本
+func writeStub(t *testing.T, dir, name string, exitCode int) string {
+	t.Helper()
+	logPath := filepath.Join(dir, name+".log")
+	script := "#!/bin/sh\necho \"$@\" >> '" + logPath + "'\nexit " + strconv.Itoa(exitCode) + "\n"
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0755); err != nil {
+		t.Fatalf("write stub %s: %v", name, err)
+	}
+	return logPath
+}
+
+func stubDir(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("stub executables are shell scripts")
+	}
+	dir := t.TempDir()
+	t.Setenv("PATH", dir)
+	return dir
+}
+
+func readLog(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func TestDeployRunsFrontendCommand(t *testing.T) {
+	dir := stubDir(t)
+	logPath := writeStub(t, dir, "fcitx5-remote", 0)
+
+	command, err := Deploy(FrontendFcitx5)
+	if err != nil {
+		t.Fatalf("Deploy failed: %v", err)
+	}
+	if command.Name != "fcitx5-remote" {
+		t.Fatalf("command = %s; want fcitx5-remote", command)
+	}
+	if got := readLog(t, logPath); got != "-r" {
+		t.Fatalf("fcitx5-remote args = %q; want -r", got)
+	}
+}
+
+func TestDeployFallsBackToNextCommand(t *testing.T) {
+	dir := stubDir(t)
+	daemonLog := writeStub(t, dir, "ibus-daemon", 1)
+	ibusLog := writeStub(t, dir, "ibus", 0)
+
+	command, err := Deploy(FrontendIBus)
+	if err != nil {
+		t.Fatalf("Deploy failed: %v", err)
+	}
+	if command.String() != "ibus restart" {
+		t.Fatalf("command = %s; want ibus restart", command)
+	}
+	if readLog(t, daemonLog) != "-rdx" || readLog(t, ibusLog) != "restart" {
+		t.Fatalf("unexpected calls: ibus-daemon %q, ibus %q", readLog(t, daemonLog), readLog(t, ibusLog))
+	}
+}
+
+func TestDeployFlatpakUsesFcitx5Remote(t *testing.T) {
+	dir := stubDir(t)
+	logPath := writeStub(t, dir, "flatpak", 0)
+
+	if _, err := Deploy(FrontendFcitx5Flatpak); err != nil {
+		t.Fatalf("Deploy failed: %v", err)
+	}
+	if got := readLog(t, logPath); got != "run --command=fcitx5-remote org.fcitx.Fcitx5 -r" {
+		t.Fatalf("flatpak args = %q", got)
+	}
+}
+
+func TestDeployReportsFailures(t *testing.T) {
+	dir := stubDir(t)
+
+	if _, err := Deploy(FrontendFcitx5); !errors.Is(err, ErrNoDeployCommand) {
+		t.Fatalf("Deploy without commands = %v; want ErrNoDeployCommand", err)
+	}
+
+	writeStub(t, dir, "flatpak", 3)
+	_, err := Deploy(FrontendFcitx5Flatpak)
+	if err == nil || errors.Is(err, ErrNoDeployCommand) || !strings.Contains(err.Error(), "flatpak") {
+		t.Fatalf("Deploy with failing command = %v; want command failure", err)
+	}
+
+	if _, err := Deploy(FrontendUnknown); !errors.Is(err, ErrNoDeployCommand) {
+		t.Fatalf("Deploy(unknown) = %v; want ErrNoDeployCommand", err)
+	}
+}
+
+func TestDeployerIsInjectable(t *testing.T) {
+	var calls []string
+	deployer := &Deployer{
+		LookPath: func(file string) (string, error) { return "/stub/" + filepath.Base(file), nil },
+		Run: func(path string, args []string) ([]byte, error) {
+			calls = append(calls, path+" "+strings.Join(args, " "))
+			return nil, nil
+		},
+	}
+
+	if _, err := deployer.Deploy(FrontendSquirrel); err != nil {
+		t.Fatalf("Deploy failed: %v", err)
+	}
+	if want := []string{"/stub/Squirrel --reload"}; !reflect.DeepEqual(calls, want) {
+		t.Fatalf("calls = %v; want %v", calls, want)
+	}
+}
+
+func TestFrontendForDir(t *testing.T) {
+	tests := map[string]Frontend{
+		"/home/u/.config/ibus/rime":                          FrontendIBus,
+		"/home/u/.local/share/fcitx5/rime":                   FrontendFcitx5,
+		"/home/u/.var/app/org.fcitx.Fcitx5/data/fcitx5/rime": FrontendFcitx5Flatpak,
+		"/home/u/.config/fcitx/rime":                         FrontendFcitx,
+		"/Users/u/Library/Rime/":                             FrontendSquirrel,
+	}
+	for dir, want := range tests {
+		if got := FrontendForDir(dir); got != want {
+			t.Errorf("FrontendForDir(%q) = %q; want %q", dir, got, want)
+		}
+	}
+}
+
+func TestParseFrontend(t *testing.T) {
+	if frontend, err := ParseFrontend(" Fcitx5-Flatpak "); err != nil || frontend != FrontendFcitx5Flatpak {
+		t.Fatalf("ParseFrontend = %q, %v", frontend, err)
+	}
+	if _, err := ParseFrontend("fcitx4"); err == nil {
+		t.Fatal("ParseFrontend accepted an unknown frontend")
+	}
+}
+
+func TestUserDirMatchesFrontendForDir(t *testing.T) {
+	t.Setenv("HOME", "/home/u")
+	for _, frontend := range []Frontend{FrontendIBus, FrontendFcitx5, FrontendFcitx5Flatpak, FrontendFcitx, FrontendSquirrel} {
+		if got := FrontendForDir(UserDir(frontend)); got != frontend {
+			t.Errorf("FrontendForDir(UserDir(%q)) = %q", frontend, got)
+		}
+	}
+}
@@ -3,7 +3,10 @@ package downloader
 import (
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
+	"net/url"
+	"path"
 	"strconv"
 	"strings"
 	"time"
@@ -84,34 +87,39 @@ func Download(url string) []byte {
 
 // DownloadWithCallback 带进度回调的下载函数
 func DownloadWithCallback(url string, callback ProgressCallback) []byte {
+	data, _ := DownloadFile(url, callback)
+	return data
+}
+
+// DownloadFile 下载文件，同时返回服务器建议的文件名（Content-Disposition 或 URL 中的文件名）
+func DownloadFile(url string, callback ProgressCallback) ([]byte, string) {
 	fmt.Printf("正在下载: %s\n", url)
 
 	// 创建HTTP请求
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := newRequest("GET", url)
 	if err != nil {
 		fmt.Printf("\n创建请求失败: %v\n", err)
-		return nil
+		return nil, ""
 	}
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
 		fmt.Printf("\n请求失败: %v\n", err)
-		return nil
+		return nil, ""
 	}
 	defer resp.Body.Close()
 
 	// 检查HTTP状态码
 	if resp.StatusCode != http.StatusOK {
 		fmt.Printf("\nHTTP错误: %s\n", resp.Status)
-		return nil
+		return nil, ""
 	}
 
 	// 防止下载 HTML 登录页或错误页 (如 5.2MB 的回退页面)
 	contentType := resp.Header.Get("Content-Type")
 	if strings.Contains(contentType, "text/html") {
 		fmt.Printf("\n错误: 下载内容是 HTML 页面，而不是文件。链接可能无效或需要权限认证。\n")
-		return nil
+		return nil, ""
 	}
 
 	// 获取文件大小
@@ -134,11 +142,36 @@ func DownloadWithCallback(url string, callback ProgressCallback) []byte {
 	data, err := io.ReadAll(progressReader)
 	if err != nil {
 		fmt.Printf("\n读取内容失败: %v\n", err)
-		return nil
+		return nil, ""
 	}
 
 	fmt.Printf("\n✅ 下载完成! 总大小: %s\n", FormatBytes(int64(len(data))))
-	return data
+	return data, responseFileName(resp, url)
+}
+
+// responseFileName 优先使用 Content-Disposition 中的文件名，否则取 URL 路径的最后一段
+func responseFileName(resp *http.Response, rawURL string) string {
+	if disposition := resp.Header.Get("Content-Disposition"); disposition != "" {
+		if _, params, err := mime.ParseMediaType(disposition); err == nil {
+			if name := path.Base(strings.ReplaceAll(params["filename"], "\\", "/")); name != "." && name != "/" {
+				return name
+			}
+		}
+	}
+	return FileNameFromURL(rawURL)
+}
+
+// FileNameFromURL 返回 URL 路径中的文件名，无法解析时返回空字符串
+func FileNameFromURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	name := path.Base(parsed.Path)
+	if name == "." || name == "/" {
+		return ""
+	}
+	return name
 }
 
 // FormatBytes 格式化字节大小
@@ -0,0 +1,109 @@
+package downloader
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDownloadFileName(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/download" {
+			w.Header().Set("Content-Disposition", `attachment; filename="wanxiang-lts-zh-hant.gram"`)
+		}
+		w.Write([]byte("gram"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/download", want: "wanxiang-lts-zh-hant.gram"},
+		{path: "/releases/custom%20model.gram?token=1", want: "custom model.gram"},
+	}
+	for _, tt := range tests {
+		data, name := DownloadFile(server.URL+tt.path, nil)
+		if string(data) != "gram" {
+			t.Fatalf("DownloadFile(%s) data = %q", tt.path, data)
+		}
+		if name != tt.want {
+			t.Errorf("DownloadFile(%s) name = %q; want %q", tt.path, name, tt.want)
+		}
+	}
+}
+
+func TestFileNameFromURL(t *testing.T) {
+	tests := map[string]string{
+		"https://example.com/a/b/model.gram": "model.gram",
+		"https://example.com/":               "",
+		"https://example.com":                "",
+	}
+	for rawURL, want := range tests {
+		if got := FileNameFromURL(rawURL); got != want {
+			t.Errorf("FileNameFromURL(%q) = %q; want %q", rawURL, got, want)
+		}
+	}
+}
+
+func TestSetProxy(t *testing.T) {
+	// 代理服务器直接返回内容，能收到说明请求经过了代理
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Host != "example.invalid" {
+			http.Error(w, "unexpected host", http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte("via proxy"))
+	}))
+	defer proxy.Close()
+
+	if err := SetProxy(proxy.URL); err != nil {
+		t.Fatalf("SetProxy returned error: %v", err)
+	}
+	defer SetProxy("")
+
+	if data := Download("http://example.invalid/a.zip"); string(data) != "via proxy" {
+		t.Fatalf("Download = %q; want via proxy", data)
+	}
+
+	for _, invalid := range []string{"ftp://proxy:21", "not a url"} {
+		if err := SetProxy(invalid); err == nil {
+			t.Errorf("SetProxy(%q) returned nil; want error", invalid)
+		}
+	}
+}
+
+func TestProbeFollowsLatestRedirect(t *testing.T) {
+	modified := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/releases/download/latest/oh-my-rime.zip":
+			http.Redirect(w, r, "/releases/download/v2026.03.01/oh-my-rime.zip", http.StatusFound)
+		case "/releases/download/v2026.03.01/oh-my-rime.zip":
+			if r.Header.Get("Range") != "bytes=0-0" {
+				t.Errorf("Range = %q; want bytes=0-0", r.Header.Get("Range"))
+			}
+			w.Header().Set("ETag", `"abc"`)
+			w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+			w.Header().Set("Content-Range", "bytes 0-0/12345")
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte("P"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	info, err := Probe(server.URL + "/releases/download/latest/oh-my-rime.zip")
+	if err != nil {
+		t.Fatalf("Probe returned error: %v", err)
+	}
+	if info.Version != "v2026.03.01" || info.ETag != `"abc"` || info.Size != 12345 || !info.LastModified.Equal(modified) {
+		t.Fatalf("Probe = %+v", info)
+	}
+
+	if _, err := Probe(server.URL + "/missing.zip"); err == nil {
+		t.Fatal("Probe(missing) returned nil; want error")
+	}
+}
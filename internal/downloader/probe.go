@@ -0,0 +1,83 @@
+package downloader
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// RemoteInfo 远程文件的版本信息，通过只请求一个字节得到，不下载文件内容
+type RemoteInfo struct {
+	// URL 请求的地址
+	URL string `json:"url"`
+	// Version 发布版本号，从重定向经过的地址中得到（latest 不算版本号），无法得到时为空
+	Version string `json:"version,omitempty"`
+	ETag    string `json:"etag,omitempty"`
+	// Size 文件大小，未知时为 0
+	Size int64 `json:"size,omitempty"`
+	// LastModified 文件的发布（最后修改）时间
+	LastModified time.Time `json:"lastModified,omitempty"`
+}
+
+// 发布地址中的版本号，如 .../releases/download/v1.2.3/oh-my-rime.zip
+var releaseTagPattern = regexp.MustCompile(`/releases/download/([^/]+)/`)
+
+// ReleaseTag 返回发布地址中的版本号（可能是 latest），不是发布地址时返回空字符串
+func ReleaseTag(url string) string {
+	if match := releaseTagPattern.FindStringSubmatch(url); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// Probe 查询远程文件的版本、大小和发布时间。使用 Range 请求代替 HEAD，部分发布服务不支持 HEAD
+func Probe(url string) (*RemoteInfo, error) {
+	req, err := newRequest("GET", url)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", "bytes=0-0")
+
+	info := &RemoteInfo{URL: url}
+	info.setVersion(url)
+	// latest 地址会重定向到具体版本的地址，从中得到版本号
+	probeClient := *client
+	probeClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return fmt.Errorf("重定向次数过多")
+		}
+		info.setVersion(req.URL.String())
+		return nil
+	}
+
+	resp, err := probeClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		if _, size, err := parseContentRange(resp.Header.Get("Content-Range")); err == nil {
+			info.Size = size
+		}
+	case http.StatusOK:
+		// 不支持 Range 的服务器返回完整文件，只读取响应头
+		info.Size, _ = strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	default:
+		return nil, fmt.Errorf("HTTP错误: %s", resp.Status)
+	}
+	info.ETag = resp.Header.Get("ETag")
+	if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.LastModified = modified
+	}
+	return info, nil
+}
+
+func (info *RemoteInfo) setVersion(url string) {
+	if tag := ReleaseTag(url); tag != "" && tag != "latest" {
+		info.Version = tag
+	}
+}
@@ -0,0 +1,31 @@
+package downloader
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// client 下载使用的 HTTP 客户端，默认遵循 HTTP_PROXY、HTTPS_PROXY 环境变量
+var client = http.DefaultClient
+
+// SetProxy 设置下载使用的代理（http、https 或 socks5 地址），为空时恢复默认的环境变量代理
+func SetProxy(proxy string) error {
+	if proxy == "" {
+		client = http.DefaultClient
+		return nil
+	}
+	proxyURL, err := url.Parse(proxy)
+	if err != nil || proxyURL.Host == "" {
+		return fmt.Errorf("代理地址无效: %s", proxy)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return fmt.Errorf("不支持的代理协议: %s（可选 http、https、socks5）", proxyURL.Scheme)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyURL(proxyURL)
+	client = &http.Client{Transport: transport}
+	return nil
+}
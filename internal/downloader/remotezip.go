@@ -0,0 +1,294 @@
+package downloader
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// 首次请求 zip 末尾的字节数，通常已包含完整的中央目录
+	remoteZipTailSize = 256 * 1024
+	// 缓存未命中时按块请求
+	remoteZipBlockSize = 64 * 1024
+	// 请求条目数据时多取的字节数，覆盖下一个条目的本地文件头，减少请求次数
+	remoteZipHeaderSlack = 1024
+)
+
+// DownloadZipEntries 只下载 zip 中名称满足 match 的条目，返回仅包含这些条目的 zip 数据。
+// 先通过 Range 请求读取中央目录，再逐个下载所需条目；服务器不支持 Range 请求时回退为完整下载
+func DownloadZipEntries(url string, match func(name string) bool, callback ProgressCallback) []byte {
+	fmt.Printf("正在按需下载: %s\n", url)
+
+	remote, full, err := openRemoteZip(url)
+	if err != nil {
+		fmt.Printf("按需下载失败 (%v)，改为完整下载\n", err)
+		return DownloadWithCallback(url, callback)
+	}
+	if full != nil {
+		// 服务器忽略了 Range 请求并返回了完整文件
+		fmt.Println("服务器不支持 Range 请求，改为完整下载")
+		return readFullResponse(full, callback)
+	}
+
+	data, err := remote.extractEntries(match, callback)
+	if err != nil {
+		fmt.Printf("\n按需下载失败 (%v)，改为完整下载\n", err)
+		return DownloadWithCallback(url, callback)
+	}
+	fmt.Printf("\n✅ 下载完成! 共请求 %d 次，传输 %s（完整文件 %s）\n",
+		remote.requests, FormatBytes(remote.transferred), FormatBytes(remote.size))
+	return data
+}
+
+// remoteZip 通过 HTTP Range 请求实现 io.ReaderAt，已下载的区间会被缓存
+type remoteZip struct {
+	url         string
+	size        int64
+	chunks      []remoteChunk
+	requests    int
+	transferred int64
+	progress    *ProgressReader
+}
+
+type remoteChunk struct {
+	start int64
+	data  []byte
+}
+
+func newRequest(method, url string) (*http.Request, error) {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	return req, nil
+}
+
+// openRemoteZip 请求 zip 末尾并解析文件总大小；若服务器返回 200，则返回完整响应供回退使用
+func openRemoteZip(url string) (*remoteZip, *http.Response, error) {
+	req, err := newRequest("GET", url)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=-%d", remoteZipTailSize))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil, resp, nil
+	case http.StatusPartialContent:
+	default:
+		resp.Body.Close()
+		return nil, nil, fmt.Errorf("HTTP错误: %s", resp.Status)
+	}
+	defer resp.Body.Close()
+
+	start, size, err := parseContentRange(resp.Header.Get("Content-Range"))
+	if err != nil {
+		return nil, nil, err
+	}
+	tail, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	remote := &remoteZip{url: url, size: size, requests: 1, transferred: int64(len(tail))}
+	remote.chunks = append(remote.chunks, remoteChunk{start: start, data: tail})
+	return remote, nil, nil
+}
+
+// parseContentRange 解析 "bytes start-end/size"
+func parseContentRange(value string) (int64, int64, error) {
+	var start, end, size int64
+	if _, err := fmt.Sscanf(value, "bytes %d-%d/%d", &start, &end, &size); err != nil {
+		return 0, 0, fmt.Errorf("无法解析 Content-Range: %q", value)
+	}
+	if start < 0 || end < start || end >= size {
+		return 0, 0, fmt.Errorf("Content-Range 无效: %q", value)
+	}
+	return start, size, nil
+}
+
+// ReadAt 实现 io.ReaderAt，缓存未命中时按块发起 Range 请求
+func (r *remoteZip) ReadAt(p []byte, off int64) (int, error) {
+	if off >= r.size {
+		return 0, io.EOF
+	}
+	want := int64(len(p))
+	if off+want > r.size {
+		want = r.size - off
+	}
+
+	if !r.cached(off, want) {
+		blockStart := off - off%remoteZipBlockSize
+		blockEnd := off + want
+		if blockEnd < blockStart+remoteZipBlockSize {
+			blockEnd = blockStart + remoteZipBlockSize
+		}
+		if err := r.fetch(blockStart, blockEnd, false); err != nil {
+			return 0, err
+		}
+	}
+
+	n := r.copyFromCache(p[:want], off)
+	if int64(n) < int64(len(p)) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (r *remoteZip) cached(off, length int64) bool {
+	for _, chunk := range r.chunks {
+		if off >= chunk.start && off+length <= chunk.start+int64(len(chunk.data)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *remoteZip) copyFromCache(p []byte, off int64) int {
+	for _, chunk := range r.chunks {
+		if off >= chunk.start && off+int64(len(p)) <= chunk.start+int64(len(chunk.data)) {
+			return copy(p, chunk.data[off-chunk.start:])
+		}
+	}
+	return 0
+}
+
+// fetch 请求 [start, end) 区间并加入缓存，withProgress 为 true 时计入下载进度
+func (r *remoteZip) fetch(start, end int64, withProgress bool) error {
+	if end > r.size {
+		end = r.size
+	}
+	req, err := newRequest("GET", r.url)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end-1))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent {
+		return fmt.Errorf("Range 请求失败: %s", resp.Status)
+	}
+	gotStart, _, err := parseContentRange(resp.Header.Get("Content-Range"))
+	if err != nil {
+		return err
+	}
+	if gotStart != start {
+		return fmt.Errorf("Range 响应起始位置不符: %d != %d", gotStart, start)
+	}
+
+	var body io.Reader = resp.Body
+	if withProgress && r.progress != nil {
+		r.progress.Reader = resp.Body
+		body = r.progress
+	}
+	data := make([]byte, end-start)
+	if _, err := io.ReadFull(body, data); err != nil {
+		return err
+	}
+
+	r.requests++
+	r.transferred += int64(len(data))
+	r.chunks = append(r.chunks, remoteChunk{start: start, data: data})
+	return nil
+}
+
+// extractEntries 读取中央目录，下载匹配的条目并重新打包为 zip
+func (r *remoteZip) extractEntries(match func(name string) bool, callback ProgressCallback) ([]byte, error) {
+	zipReader, err := zip.NewReader(r, r.size)
+	if err != nil {
+		return nil, fmt.Errorf("读取中央目录失败: %v", err)
+	}
+
+	var selected []*zip.File
+	var total int64
+	for _, file := range zipReader.File {
+		if match(file.Name) {
+			selected = append(selected, file)
+			total += int64(file.CompressedSize64) + remoteZipHeaderSlack
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("zip 中没有需要的条目")
+	}
+	fmt.Printf("需要下载 %d 个条目，共 %s\n", len(selected), FormatBytes(total))
+
+	now := time.Now()
+	r.progress = &ProgressReader{Total: total, StartTime: now, LastUpdate: now, Callback: callback}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, file := range selected {
+		offset, err := file.DataOffset()
+		if err != nil {
+			return nil, err
+		}
+		// 一次请求取回条目数据及下一个条目的文件头
+		dataEnd := offset + int64(file.CompressedSize64)
+		if !r.cached(offset, int64(file.CompressedSize64)) {
+			if err := r.fetch(offset, dataEnd+remoteZipHeaderSlack, true); err != nil {
+				return nil, err
+			}
+		}
+
+		raw, err := file.OpenRaw()
+		if err != nil {
+			return nil, err
+		}
+		header := file.FileHeader
+		w, err := zw.CreateRaw(&header)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.Copy(w, raw); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// readFullResponse 读取完整响应（用于服务器不支持 Range 时的回退）
+func readFullResponse(resp *http.Response, callback ProgressCallback) []byte {
+	defer resp.Body.Close()
+
+	if strings.Contains(resp.Header.Get("Content-Type"), "text/html") {
+		fmt.Printf("\n错误: 下载内容是 HTML 页面，而不是文件。链接可能无效或需要权限认证。\n")
+		return nil
+	}
+
+	var totalSize int64
+	if contentLength := resp.Header.Get("Content-Length"); contentLength != "" {
+		totalSize, _ = strconv.ParseInt(contentLength, 10, 64)
+	}
+	now := time.Now()
+	data, err := io.ReadAll(&ProgressReader{
+		Reader:     resp.Body,
+		Total:      totalSize,
+		StartTime:  now,
+		LastUpdate: now,
+		Callback:   callback,
+	})
+	if err != nil {
+		fmt.Printf("\n读取内容失败: %v\n", err)
+		return nil
+	}
+	fmt.Printf("\n✅ 下载完成! 总大小: %s\n", FormatBytes(int64(len(data))))
+	return data
+}
@@ -0,0 +1,135 @@
+package downloader
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func buildTestZip(t *testing.T) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	write := func(name string, body []byte) {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("create zip entry: %v", err)
+		}
+		if _, err := w.Write(body); err != nil {
+			t.Fatalf("write zip entry: %v", err)
+		}
+	}
+
+	// 不可压缩的大文件，模拟方案包中的其他内容
+	noise := make([]byte, 2<<20)
+	rand.New(rand.NewSource(1)).Read(noise)
+	write("wanxiang-lts-zh-hans.gram", noise)
+	write("default.yaml", []byte("config_version: 1"))
+	write("dicts/base.dict.yaml", []byte("---\nname: base\n...\n你好\tni hao\n"))
+	write("dicts/ext.dict.yaml", []byte("---\nname: ext\n...\n世界\tshi jie\n"))
+	write("lua/helper.lua", noise[:512*1024])
+
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func isDict(name string) bool {
+	return strings.HasPrefix(name, "dicts/")
+}
+
+func readZipEntries(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+
+	zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("read zip: %v", err)
+	}
+	entries := make(map[string]string)
+	for _, file := range zipReader.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("open entry %s: %v", file.Name, err)
+		}
+		body, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("read entry %s: %v", file.Name, err)
+		}
+		entries[file.Name] = string(body)
+	}
+	return entries
+}
+
+func TestDownloadZipEntriesUsesRangeRequests(t *testing.T) {
+	full := buildTestZip(t)
+	var transferred int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		counter := &countingWriter{ResponseWriter: w, count: &transferred}
+		http.ServeContent(counter, r, "oh-my-rime.zip", time.Time{}, bytes.NewReader(full))
+	}))
+	defer server.Close()
+
+	data := DownloadZipEntries(server.URL+"/oh-my-rime.zip", isDict, nil)
+	if data == nil {
+		t.Fatal("DownloadZipEntries returned nil")
+	}
+
+	entries := readZipEntries(t, data)
+	if len(entries) != 2 {
+		t.Fatalf("entries = %v; want only dicts", entries)
+	}
+	if got := entries["dicts/ext.dict.yaml"]; got != "---\nname: ext\n...\n世界\tshi jie\n" {
+		t.Fatalf("ext dict = %q", got)
+	}
+	if transferred >= int64(len(full))/2 {
+		t.Fatalf("transferred %d bytes of %d; want partial download", transferred, len(full))
+	}
+}
+
+func TestDownloadZipEntriesFallsBackWithoutRangeSupport(t *testing.T) {
+	full := buildTestZip(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 忽略 Range 头，总是返回完整文件
+		w.Header().Set("Content-Type", "application/zip")
+		w.Write(full)
+	}))
+	defer server.Close()
+
+	data := DownloadZipEntries(server.URL+"/oh-my-rime.zip", isDict, nil)
+	if !bytes.Equal(data, full) {
+		t.Fatalf("fallback returned %d bytes; want full zip of %d bytes", len(data), len(full))
+	}
+}
+
+func TestDownloadZipEntriesFallsBackOnInvalidZip(t *testing.T) {
+	body := bytes.Repeat([]byte("not a zip"), 1000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "broken.zip", time.Time{}, bytes.NewReader(body))
+	}))
+	defer server.Close()
+
+	data := DownloadZipEntries(server.URL+"/broken.zip", isDict, nil)
+	if !bytes.Equal(data, body) {
+		t.Fatalf("fallback returned %d bytes; want full body of %d bytes", len(data), len(body))
+	}
+}
+
+type countingWriter struct {
+	http.ResponseWriter
+	count *int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	atomic.AddInt64(w.count, int64(len(p)))
+	return w.ResponseWriter.Write(p)
+}
@@ -0,0 +1,91 @@
+package releases
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"oh-my-rime-cli/internal/system"
+)
+
+// CacheDir 发布列表（含发布说明）的本地缓存目录
+func CacheDir() string {
+	return filepath.Join(system.AppConfigDir(), "releases")
+}
+
+// 缓存文件名中不允许的字符
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// CachePath 返回发布文件地址对应的缓存文件，同一仓库的文件共用；不是发布文件地址时返回空字符串
+func CachePath(downloadURL string) string {
+	match := downloadPattern.FindStringSubmatch(downloadURL)
+	if match == nil {
+		return ""
+	}
+	return filepath.Join(CacheDir(), unsafeNameChars.ReplaceAllString(match[1]+"/"+match[2], "_")+".json")
+}
+
+type cacheFile struct {
+	FetchedAt time.Time `json:"fetchedAt"`
+	Releases  []Release `json:"releases"`
+}
+
+// Cached 在线获取成功时把发布列表保存到 Path，失败时读取上次保存的列表，以便离线查看发布说明
+type Cached struct {
+	Source Source
+	Path   string
+	// Offline 最近一次 Releases 是否读取了缓存
+	Offline bool
+	// FetchedAt 返回的列表获取的时间
+	FetchedAt time.Time
+}
+
+// Releases 实现 Source，发布源和缓存都不可用时返回发布源的错误
+func (c *Cached) Releases() ([]Release, error) {
+	list, err := c.Source.Releases()
+	if err == nil {
+		c.Offline, c.FetchedAt = false, time.Now()
+		if c.Path != "" {
+			if saveErr := saveCache(c.Path, cacheFile{FetchedAt: c.FetchedAt, Releases: list}); saveErr != nil {
+				fmt.Printf("⚠️  缓存发布说明失败: %v\n", saveErr)
+			}
+		}
+		return list, nil
+	}
+	if c.Path == "" {
+		return nil, err
+	}
+	data, readErr := os.ReadFile(c.Path)
+	if readErr != nil {
+		return nil, err
+	}
+	var cached cacheFile
+	if json.Unmarshal(data, &cached) != nil {
+		return nil, err
+	}
+	c.Offline, c.FetchedAt = true, cached.FetchedAt
+	return cached.Releases, nil
+}
+
+func saveCache(path string, cached cacheFile) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cached, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// CachedForURL 与 ForURL 相同，返回的发布源会缓存发布列表
+func CachedForURL(downloadURL string) (Source, string, error) {
+	source, name, err := ForURL(downloadURL)
+	if err != nil {
+		return nil, "", err
+	}
+	return &Cached{Source: source, Path: CachePath(downloadURL)}, name, nil
+}
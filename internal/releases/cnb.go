@@ -0,0 +1,71 @@
+package releases
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"oh-my-rime-cli/internal/downloader"
+)
+
+// CNBAPI cnb.cool OpenAPI 地址
+const CNBAPI = "https://api.cnb.cool"
+
+// CNB cnb.cool 仓库的发布列表，Repo 为完整的仓库路径，如 Mintimate/rime/oh-my-rime。
+// 公开仓库无需令牌，设置 CNB_TOKEN 环境变量时使用令牌访问
+type CNB struct {
+	Repo string
+	// BaseURL 接口地址，为空时使用 CNBAPI
+	BaseURL string
+}
+
+type cnbRelease struct {
+	TagName     string    `json:"tag_name"`
+	Name        string    `json:"name"`
+	Body        string    `json:"body"`
+	Draft       bool      `json:"draft"`
+	PublishedAt time.Time `json:"published_at"`
+	Assets      []struct {
+		Name string `json:"name"`
+		// Path 文件在仓库下的路径，如 /-/releases/download/v1.0.0/oh-my-rime.zip
+		Path string `json:"path"`
+		Size int64  `json:"size"`
+	} `json:"assets"`
+}
+
+// Releases 实现 Source，返回最近的 100 个发布
+func (c *CNB) Releases() ([]Release, error) {
+	base := c.BaseURL
+	if base == "" {
+		base = CNBAPI
+	}
+	header := http.Header{"Accept": {"application/vnd.cnb.api+json"}}
+	if token := os.Getenv("CNB_TOKEN"); token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+
+	var list []cnbRelease
+	url := fmt.Sprintf("%s/%s/-/releases?page=1&page_size=100", base, c.Repo)
+	if err := downloader.GetJSON(url, header, &list); err != nil {
+		return nil, fmt.Errorf("获取 cnb.cool 发布列表失败: %v", err)
+	}
+
+	var result []Release
+	for _, item := range list {
+		if item.Draft {
+			continue
+		}
+		release := Release{Tag: item.TagName, Name: item.Name, PublishedAt: item.PublishedAt, Notes: item.Body}
+		for _, asset := range item.Assets {
+			url := asset.Path
+			if !strings.HasPrefix(url, "http") {
+				url = fmt.Sprintf("https://cnb.cool/%s/-/releases/download/%s/%s", c.Repo, item.TagName, asset.Name)
+			}
+			release.Assets = append(release.Assets, Asset{Name: asset.Name, URL: url, Size: asset.Size})
+		}
+		result = append(result, release)
+	}
+	return result, nil
+}
@@ -0,0 +1,65 @@
+package releases
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"oh-my-rime-cli/internal/downloader"
+)
+
+// GitHubAPI GitHub REST API 地址
+const GitHubAPI = "https://api.github.com"
+
+// GitHub GitHub 仓库的发布列表。设置 GITHUB_TOKEN 环境变量可提高接口的调用次数限制
+type GitHub struct {
+	Owner string
+	Repo  string
+	// BaseURL 接口地址，为空时使用 GitHubAPI
+	BaseURL string
+}
+
+type githubRelease struct {
+	TagName     string    `json:"tag_name"`
+	Name        string    `json:"name"`
+	Body        string    `json:"body"`
+	Draft       bool      `json:"draft"`
+	PublishedAt time.Time `json:"published_at"`
+	Assets      []struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+		Size int64  `json:"size"`
+	} `json:"assets"`
+}
+
+// Releases 实现 Source，返回最近的 100 个发布
+func (g *GitHub) Releases() ([]Release, error) {
+	base := g.BaseURL
+	if base == "" {
+		base = GitHubAPI
+	}
+	header := http.Header{"Accept": {"application/vnd.github+json"}}
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+
+	var list []githubRelease
+	url := fmt.Sprintf("%s/repos/%s/%s/releases?per_page=100", base, g.Owner, g.Repo)
+	if err := downloader.GetJSON(url, header, &list); err != nil {
+		return nil, fmt.Errorf("获取 GitHub 发布列表失败: %v", err)
+	}
+
+	var result []Release
+	for _, item := range list {
+		if item.Draft {
+			continue
+		}
+		release := Release{Tag: item.TagName, Name: item.Name, PublishedAt: item.PublishedAt, Notes: item.Body}
+		for _, asset := range item.Assets {
+			release.Assets = append(release.Assets, Asset{Name: asset.Name, URL: asset.URL, Size: asset.Size})
+		}
+		result = append(result, release)
+	}
+	return result, nil
+}
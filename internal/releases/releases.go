@@ -0,0 +1,113 @@
+package releases
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Release 发布源中的一次发布
+type Release struct {
+	Tag         string    `json:"tag"`
+	Name        string    `json:"name,omitempty"`
+	PublishedAt time.Time `json:"publishedAt"`
+	// Notes 发布说明（Markdown）
+	Notes  string  `json:"notes,omitempty"`
+	Assets []Asset `json:"assets,omitempty"`
+}
+
+// Asset 发布中的一个文件
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	Size int64  `json:"size"`
+}
+
+// FindAsset 按文件名查找发布中的文件
+func (r Release) FindAsset(name string) (Asset, bool) {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset, true
+		}
+	}
+	return Asset{}, false
+}
+
+// Source 发布源，Releases 按发布时间从新到旧返回
+type Source interface {
+	Releases() ([]Release, error)
+}
+
+// Static 固定的发布列表，用于测试和离线缓存
+type Static []Release
+
+// Releases 实现 Source
+func (s Static) Releases() ([]Release, error) {
+	return s, nil
+}
+
+// 发布文件地址，如 https://cnb.cool/Mintimate/rime/oh-my-rime/-/releases/download/latest/oh-my-rime.zip
+// 或 https://github.com/amzxyz/RIME-LMDG/releases/download/LTS/wanxiang-lts-zh-hant.gram
+var downloadPattern = regexp.MustCompile(`^(https?://[^/]+)/(.+?)(/-)?/releases/download/([^/]+)/([^/]+)$`)
+
+// 发布文件地址中的版本号，镜像地址可能没有仓库路径
+var tagPattern = regexp.MustCompile(`/releases/download/([^/]+)/[^/]+$`)
+
+// ForURL 根据发布文件的下载地址返回对应的发布源与文件名，支持 cnb.cool 与 GitHub
+func ForURL(downloadURL string) (Source, string, error) {
+	match := downloadPattern.FindStringSubmatch(downloadURL)
+	if match == nil {
+		return nil, "", fmt.Errorf("不是发布文件的地址，无法列出版本: %s", downloadURL)
+	}
+	host, repo, assetName := match[1], match[2], match[5]
+	parsed, err := url.Parse(host)
+	if err != nil {
+		return nil, "", err
+	}
+	switch parsed.Hostname() {
+	case "github.com":
+		owner, name, ok := strings.Cut(repo, "/")
+		if !ok || strings.Contains(name, "/") {
+			return nil, "", fmt.Errorf("无法识别 GitHub 仓库: %s", repo)
+		}
+		return &GitHub{Owner: owner, Repo: name}, assetName, nil
+	case "cnb.cool":
+		return &CNB{Repo: repo}, assetName, nil
+	}
+	return nil, "", fmt.Errorf("不支持列出此发布源的版本（支持 cnb.cool、GitHub）: %s", parsed.Hostname())
+}
+
+// AssetURL 把发布文件地址中的版本换成 tag，如 latest 换成 v1.2.3；不是发布文件地址时返回错误
+func AssetURL(downloadURL, tag string) (string, error) {
+	match := tagPattern.FindStringSubmatchIndex(downloadURL)
+	if match == nil {
+		return "", fmt.Errorf("不是发布文件的地址，无法指定版本: %s", downloadURL)
+	}
+	start, end := match[2], match[3]
+	return downloadURL[:start] + url.PathEscape(tag) + downloadURL[end:], nil
+}
+
+// Between 返回 releases（从新到旧）中晚于 from、不晚于 to 的发布；from 为空时只返回 to，
+// 找不到 from 时返回 to 及之前的全部发布
+func Between(releases []Release, from, to string) []Release {
+	var result []Release
+	started := false
+	for _, release := range releases {
+		if release.Tag == to {
+			started = true
+		}
+		if !started {
+			continue
+		}
+		if release.Tag == from {
+			break
+		}
+		result = append(result, release)
+		if from == "" {
+			break
+		}
+	}
+	return result
+}
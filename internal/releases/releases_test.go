@@ -0,0 +1,157 @@
+package releases
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestForURL(t *testing.T) {
+	source, name, err := ForURL("https://cnb.cool/Mintimate/rime/oh-my-rime/-/releases/download/latest/oh-my-rime.zip")
+	if err != nil {
+		t.Fatalf("ForURL returned error: %v", err)
+	}
+	if cnb, ok := source.(*CNB); !ok || cnb.Repo != "Mintimate/rime/oh-my-rime" || name != "oh-my-rime.zip" {
+		t.Fatalf("ForURL = %#v, %q", source, name)
+	}
+
+	source, name, err = ForURL("https://github.com/amzxyz/RIME-LMDG/releases/download/LTS/wanxiang-lts-zh-hant.gram")
+	if err != nil {
+		t.Fatalf("ForURL returned error: %v", err)
+	}
+	if gh, ok := source.(*GitHub); !ok || gh.Owner != "amzxyz" || gh.Repo != "RIME-LMDG" || name != "wanxiang-lts-zh-hant.gram" {
+		t.Fatalf("ForURL = %#v, %q", source, name)
+	}
+
+	if _, _, err := ForURL("https://example.com/oh-my-rime.zip"); err == nil {
+		t.Fatal("ForURL accepted a URL that is not a release asset")
+	}
+}
+
+func TestAssetURL(t *testing.T) {
+	got, err := AssetURL("https://cnb.cool/Mintimate/rime/oh-my-rime/-/releases/download/latest/oh-my-rime.zip", "v2026.01.01")
+	if err != nil {
+		t.Fatalf("AssetURL returned error: %v", err)
+	}
+	if want := "https://cnb.cool/Mintimate/rime/oh-my-rime/-/releases/download/v2026.01.01/oh-my-rime.zip"; got != want {
+		t.Fatalf("AssetURL = %q; want %q", got, want)
+	}
+	if _, err := AssetURL("https://example.com/oh-my-rime.zip", "v1"); err == nil {
+		t.Fatal("AssetURL accepted a URL that is not a release asset")
+	}
+}
+
+func TestBetween(t *testing.T) {
+	list := Static{{Tag: "v4"}, {Tag: "v3"}, {Tag: "v2"}, {Tag: "v1"}}
+	tags := func(releases []Release) []string {
+		var tags []string
+		for _, release := range releases {
+			tags = append(tags, release.Tag)
+		}
+		return tags
+	}
+	cases := []struct {
+		from, to string
+		want     []string
+	}{
+		{"v1", "v3", []string{"v3", "v2"}},
+		{"", "v2", []string{"v2"}},
+		{"v0", "v2", []string{"v2", "v1"}},
+		{"v3", "v3", nil},
+	}
+	for _, c := range cases {
+		got := tags(Between(list, c.from, c.to))
+		if len(got) != len(c.want) {
+			t.Fatalf("Between(%q, %q) = %v; want %v", c.from, c.to, got, c.want)
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Fatalf("Between(%q, %q) = %v; want %v", c.from, c.to, got, c.want)
+			}
+		}
+	}
+}
+
+func TestAPISources(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/repos/owner/repo/releases":
+			w.Write([]byte(`[
+				{"tag_name": "draft", "draft": true},
+				{"tag_name": "v2", "name": "v2", "body": "notes", "published_at": "2026-04-01T00:00:00Z",
+				 "assets": [{"name": "a.gram", "browser_download_url": "https://github.com/owner/repo/releases/download/v2/a.gram", "size": 10}]}
+			]`))
+		case "/group/repo/-/releases":
+			w.Write([]byte(`[
+				{"tag_name": "v1", "body": "cnb notes", "published_at": "2026-03-01T00:00:00Z",
+				 "assets": [{"name": "oh-my-rime.zip", "path": "/-/releases/download/v1/oh-my-rime.zip", "size": 20}]}
+			]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	list, err := (&GitHub{Owner: "owner", Repo: "repo", BaseURL: server.URL}).Releases()
+	if err != nil {
+		t.Fatalf("GitHub.Releases returned error: %v", err)
+	}
+	if len(list) != 1 || list[0].Tag != "v2" || list[0].Notes != "notes" || list[0].Assets[0].Size != 10 {
+		t.Fatalf("GitHub.Releases = %+v", list)
+	}
+
+	list, err = (&CNB{Repo: "group/repo", BaseURL: server.URL}).Releases()
+	if err != nil {
+		t.Fatalf("CNB.Releases returned error: %v", err)
+	}
+	asset, ok := list[0].FindAsset("oh-my-rime.zip")
+	if len(list) != 1 || !ok || asset.URL != "https://cnb.cool/group/repo/-/releases/download/v1/oh-my-rime.zip" {
+		t.Fatalf("CNB.Releases = %+v", list)
+	}
+
+	if _, err := (&CNB{Repo: "missing", BaseURL: server.URL}).Releases(); err == nil {
+		t.Fatal("CNB.Releases returned no error for a missing repository")
+	}
+}
+
+// failingSource 模拟无法连接的发布源
+type failingSource struct{}
+
+func (failingSource) Releases() ([]Release, error) {
+	return nil, errors.New("offline")
+}
+
+func TestCachedFallsBackToCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "releases.json")
+	online := &Cached{Source: Static{{Tag: "v1", Notes: "first"}}, Path: path}
+	if _, err := online.Releases(); err != nil {
+		t.Fatalf("Releases returned error: %v", err)
+	}
+
+	offline := &Cached{Source: failingSource{}, Path: path}
+	list, err := offline.Releases()
+	if err != nil {
+		t.Fatalf("Releases returned error: %v", err)
+	}
+	if !offline.Offline || len(list) != 1 || list[0].Notes != "first" {
+		t.Fatalf("cached releases = %+v, offline = %v", list, offline.Offline)
+	}
+
+	missing := &Cached{Source: failingSource{}, Path: filepath.Join(t.TempDir(), "missing.json")}
+	if _, err := missing.Releases(); err == nil {
+		t.Fatal("Releases returned no error without network or cache")
+	}
+}
+
+func TestCachePath(t *testing.T) {
+	path := CachePath("https://cnb.cool/Mintimate/rime/oh-my-rime/-/releases/download/latest/oh-my-rime.zip")
+	if filepath.Base(path) != "https_cnb.cool_Mintimate_rime_oh-my-rime.json" {
+		t.Fatalf("CachePath = %q", path)
+	}
+	if CachePath("https://example.com/oh-my-rime.zip") != "" {
+		t.Fatal("CachePath returned a path for a URL that is not a release asset")
+	}
+}
@@ -0,0 +1,259 @@
+package rimelog
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"oh-my-rime-cli/internal/deploy"
+)
+
+// 问题分类
+const (
+	CategorySchema = "schema"
+	CategoryDict   = "dict"
+	CategoryLua    = "lua"
+	CategoryConfig = "config"
+)
+
+// Entry 日志中的一条警告或错误
+type Entry struct {
+	Severity string    `json:"severity"`
+	Time     time.Time `json:"time"`
+	// Source 输出日志的源文件与行号，如 dict_compiler.cc:123
+	Source   string `json:"source"`
+	Message  string `json:"message"`
+	Category string `json:"category"`
+}
+
+func (e Entry) String() string {
+	return fmt.Sprintf("[%s] %s %s: %s", e.Severity, e.Time.Format("01-02 15:04:05"), e.Source, e.Message)
+}
+
+// Report 日志检查结果
+type Report struct {
+	// LogFiles 检查过的日志文件
+	LogFiles []string `json:"logFiles"`
+	Entries  []Entry  `json:"entries"`
+}
+
+// glog 行格式：Lmmdd hh:mm:ss.uuuuuu threadid file:line] msg，新版本日期包含年份（Lyyyymmdd）
+var linePattern = regexp.MustCompile(`^([IWEF])(\d{4}|\d{8}) (\d{2}):(\d{2}):(\d{2})\.(\d+)\s+\S+\s+([^\]]+)\] (.*)$`)
+
+// glog 文件头：Log file created at: 2024/01/02 03:04:05
+var headerPattern = regexp.MustCompile(`^Log file created at: (\d{4})/`)
+
+var severities = map[string]string{"W": "WARNING", "E": "ERROR", "F": "FATAL"}
+
+// LogPrefixes 前端日志文件名前缀
+func LogPrefixes(frontend deploy.Frontend) []string {
+	switch frontend {
+	case deploy.FrontendIBus:
+		return []string{"rime.ibus."}
+	case deploy.FrontendFcitx5, deploy.FrontendFcitx5Flatpak:
+		return []string{"rime.fcitx5.", "rime.fcitx-rime."}
+	case deploy.FrontendFcitx:
+		return []string{"rime.fcitx-rime."}
+	case deploy.FrontendSquirrel:
+		return []string{"rime.squirrel."}
+	case deploy.FrontendWeasel:
+		return []string{"rime.weasel."}
+	}
+	return nil
+}
+
+// LogDirs 前端日志所在目录
+func LogDirs(frontend deploy.Frontend) []string {
+	tmp := os.TempDir()
+	switch frontend {
+	case deploy.FrontendWeasel:
+		return []string{filepath.Join(tmp, "rime.weasel")}
+	case deploy.FrontendFcitx5Flatpak:
+		// Flatpak 应用的临时目录位于 $XDG_RUNTIME_DIR/app/<应用 ID>
+		dirs := []string{tmp}
+		if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+			dirs = append(dirs, filepath.Join(runtimeDir, "app", "org.fcitx.Fcitx5"))
+		}
+		return dirs
+	}
+	return []string{tmp}
+}
+
+// FindLog 返回前端最新的日志文件：优先 INFO 日志（包含所有级别），其次 WARNING、ERROR。
+// 没有日志时返回空字符串
+func FindLog(frontend deploy.Frontend) (string, error) {
+	var newest string
+	var newestTime time.Time
+	newestRank := -1
+	for _, dir := range LogDirs(frontend) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return "", err
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			rank := logRank(name)
+			if rank < 0 || !entry.Type().IsRegular() || !hasAnyPrefix(name, LogPrefixes(frontend)) {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			if rank > newestRank || (rank == newestRank && info.ModTime().After(newestTime)) {
+				newest, newestTime, newestRank = filepath.Join(dir, name), info.ModTime(), rank
+			}
+		}
+	}
+	return newest, nil
+}
+
+// logRank glog 文件名形如 rime.ibus.host.user.log.INFO.20240102-030405.1234
+func logRank(name string) int {
+	switch {
+	case strings.Contains(name, ".log.INFO."):
+		return 2
+	case strings.Contains(name, ".log.WARNING."):
+		return 1
+	case strings.Contains(name, ".log.ERROR."):
+		return 0
+	}
+	// 小狼毫的日志文件名为 rime.weasel.*.log
+	if strings.HasSuffix(name, ".log") {
+		return 0
+	}
+	return -1
+}
+
+func hasAnyPrefix(name string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Inspect 找到前端最新的日志，提取 since 之后有关方案、词库与 Lua 的警告和错误。
+// frontend 为 FrontendUnknown 时检查所有前端
+func Inspect(frontend deploy.Frontend, since time.Time) (*Report, error) {
+	frontends := []deploy.Frontend{frontend}
+	if frontend == deploy.FrontendUnknown {
+		frontends = deploy.Frontends
+	}
+
+	report := &Report{}
+	seen := make(map[string]bool)
+	for _, candidate := range frontends {
+		path, err := FindLog(candidate)
+		if err != nil {
+			return nil, err
+		}
+		if path == "" || seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		entries, err := ParseLog(file, since)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("读取日志 %s 失败: %v", path, err)
+		}
+		report.LogFiles = append(report.LogFiles, path)
+		report.Entries = append(report.Entries, entries...)
+	}
+	sort.SliceStable(report.Entries, func(i, j int) bool {
+		return report.Entries[i].Time.Before(report.Entries[j].Time)
+	})
+	return report, nil
+}
+
+// ParseLog 解析 glog 格式的日志，返回 since 之后与方案、词库、Lua 或配置相关的警告和错误
+func ParseLog(r io.Reader, since time.Time) ([]Entry, error) {
+	year := time.Now().Year()
+	if !since.IsZero() {
+		year = since.Year()
+	}
+
+	var entries []Entry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if match := headerPattern.FindStringSubmatch(line); match != nil {
+			year, _ = strconv.Atoi(match[1])
+			continue
+		}
+
+		match := linePattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		severity, ok := severities[match[1]]
+		if !ok {
+			continue
+		}
+		category := categorize(match[7], match[8])
+		if category == "" {
+			continue
+		}
+
+		entryTime := parseTime(year, match[2:7])
+		if entryTime.Before(since) {
+			continue
+		}
+		entries = append(entries, Entry{
+			Severity: severity,
+			Time:     entryTime,
+			Source:   strings.TrimSpace(match[7]),
+			Message:  strings.TrimSpace(match[8]),
+			Category: category,
+		})
+	}
+	return entries, scanner.Err()
+}
+
+func parseTime(year int, fields []string) time.Time {
+	date := fields[0]
+	if len(date) == 8 {
+		year, _ = strconv.Atoi(date[:4])
+		date = date[4:]
+	}
+	month, _ := strconv.Atoi(date[:2])
+	day, _ := strconv.Atoi(date[2:])
+	hour, _ := strconv.Atoi(fields[1])
+	minute, _ := strconv.Atoi(fields[2])
+	second, _ := strconv.Atoi(fields[3])
+	micros, _ := strconv.Atoi(fields[4])
+	return time.Date(year, time.Month(month), day, hour, minute, second, micros*1000, time.Local)
+}
+
+// categorize 根据源文件名与消息内容判断问题类别，无关的日志返回空字符串
+func categorize(source, message string) string {
+	text := strings.ToLower(source + " " + message)
+	switch {
+	case strings.Contains(text, "lua"):
+		return CategoryLua
+	case strings.Contains(text, "dict") || strings.Contains(text, "prism") || strings.Contains(text, "table"):
+		return CategoryDict
+	case strings.Contains(text, "schema"):
+		return CategorySchema
+	case strings.Contains(text, "yaml") || strings.Contains(text, "config"):
+		return CategoryConfig
+	}
+	return ""
+}
@@ -0,0 +1,100 @@
+package rimelog
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"oh-my-rime-cli/internal/deploy"
+)
+
+const testLog = `Log file created at: 2024/03/05 10:00:00
+Running on machine: host
+Log line format: [IWEF]mmdd hh:mm:ss.uuuuuu threadid file:line] msg
+I0305 10:00:01.000001 1234 deployment_tasks.cc:100] updating schema: rime_mint
+W0305 10:00:02.000000 1234 dict_compiler.cc:50] duplicate entry in rime_mint.base.dict.yaml
+E0305 10:00:03.000000 1234 config_data.cc:80] Error parsing YAML: rime_mint.custom.yaml line 3
+E0305 10:00:04.000000 1234 lua_gears.cc:30] Lua error: attempt to index nil value
+E0305 10:00:05.000000 1234 key_binder.cc:10] invalid key binding
+W0305 09:59:00.000000 1234 schema.cc:20] missing schema: old
+`
+
+func writeLog(t *testing.T, dir, name, content string, modTime time.Time) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseLog(t *testing.T) {
+	since := time.Date(2024, 3, 5, 10, 0, 0, 0, time.Local)
+	entries, err := ParseLog(strings.NewReader(testLog), since)
+	if err != nil {
+		t.Fatalf("ParseLog failed: %v", err)
+	}
+
+	want := []struct{ severity, category, source string }{
+		{"WARNING", CategoryDict, "dict_compiler.cc:50"},
+		{"ERROR", CategoryConfig, "config_data.cc:80"},
+		{"ERROR", CategoryLua, "lua_gears.cc:30"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("entries = %+v; want %d entries", entries, len(want))
+	}
+	for i, w := range want {
+		got := entries[i]
+		if got.Severity != w.severity || got.Category != w.category || got.Source != w.source {
+			t.Errorf("entry %d = %+v; want %+v", i, got, w)
+		}
+	}
+	if got := entries[0].Time; !got.Equal(time.Date(2024, 3, 5, 10, 0, 2, 0, time.Local)) {
+		t.Errorf("entry time = %v", got)
+	}
+}
+
+func TestParseLogWithYearInDate(t *testing.T) {
+	entries, err := ParseLog(strings.NewReader("E20231231 23:59:59.000000 1 schema.cc:1] missing schema: x\n"), time.Time{})
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("ParseLog = %+v, %v", entries, err)
+	}
+	if entries[0].Time.Year() != 2023 || entries[0].Category != CategorySchema {
+		t.Fatalf("entry = %+v", entries[0])
+	}
+}
+
+func TestInspectUsesNewestInfoLog(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("log directory is derived from TMPDIR")
+	}
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir)
+
+	now := time.Now()
+	writeLog(t, dir, "rime.ibus.host.user.log.INFO.20240301-000000.1", "E0301 00:00:00.000000 1 schema.cc:1] old schema error\n", now.Add(-time.Hour))
+	writeLog(t, dir, "rime.ibus.host.user.log.INFO.20240305-100000.2", testLog, now)
+	writeLog(t, dir, "rime.ibus.host.user.log.ERROR.20240305-100000.2", testLog, now.Add(time.Minute))
+	writeLog(t, dir, "rime.fcitx5.host.user.log.INFO.20240305-100000.3", testLog, now)
+
+	report, err := Inspect(deploy.FrontendIBus, time.Date(2024, 3, 5, 10, 0, 0, 0, time.Local))
+	if err != nil {
+		t.Fatalf("Inspect failed: %v", err)
+	}
+	if len(report.LogFiles) != 1 || filepath.Base(report.LogFiles[0]) != "rime.ibus.host.user.log.INFO.20240305-100000.2" {
+		t.Fatalf("log files = %v", report.LogFiles)
+	}
+	if len(report.Entries) != 3 {
+		t.Fatalf("entries = %+v; want 3", report.Entries)
+	}
+
+	all, err := Inspect(deploy.FrontendUnknown, time.Date(2024, 3, 5, 10, 0, 0, 0, time.Local))
+	if err != nil || len(all.LogFiles) != 2 {
+		t.Fatalf("Inspect(all) = %+v, %v; want ibus and fcitx5 logs", all, err)
+	}
+}
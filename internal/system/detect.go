@@ -0,0 +1,219 @@
+package system
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 检测得分：正在运行的进程最可靠，其次是已有的配置目录，最后是已安装的程序
+const (
+	scoreProcess   = 8
+	scoreUserDir   = 4
+	scoreInstalled = 2
+)
+
+// Process 正在运行的进程
+type Process struct {
+	Name string
+	// Flatpak 进程运行在 Flatpak 沙盒中
+	Flatpak bool
+}
+
+// Detector 探测已安装的输入法前端，各探测函数可替换以便测试
+type Detector struct {
+	// OS 操作系统，取值同 DetectOS
+	OS        string
+	Getenv    func(key string) string
+	Exists    func(path string) bool
+	LookPath  func(file string) (string, error)
+	Processes func() ([]Process, error)
+}
+
+// NewDetector 返回探测当前系统的 Detector
+func NewDetector() *Detector {
+	return &Detector{
+		OS:     DetectOS(),
+		Getenv: os.Getenv,
+		Exists: func(path string) bool {
+			_, err := os.Stat(path)
+			return err == nil
+		},
+		LookPath:  exec.LookPath,
+		Processes: listProcesses,
+	}
+}
+
+// DetectTargets 探测当前系统已安装或正在使用的输入法前端
+func DetectTargets() ([]TargetCandidate, error) {
+	return NewDetector().Detect()
+}
+
+// frontendSignals 判断前端是否存在的依据
+type frontendSignals struct {
+	// binaries PATH 中的可执行文件
+	binaries []string
+	// paths 安装后存在的文件或应用目录
+	paths []string
+	// processes 运行时的进程名
+	processes []string
+	// flatpak 进程需要（true）或不能（false）运行在 Flatpak 沙盒中
+	flatpak bool
+}
+
+func (d *Detector) signals(frontend string) frontendSignals {
+	home := d.Getenv("HOME")
+	switch frontend {
+	case "ibus":
+		return frontendSignals{
+			binaries:  []string{"ibus-engine-rime"},
+			paths:     []string{"/usr/lib/ibus/ibus-engine-rime", "/usr/libexec/ibus-engine-rime", "/usr/lib/ibus-rime/ibus-engine-rime"},
+			processes: []string{"ibus-engine-rime"},
+		}
+	case "fcitx5":
+		if d.OS == "Darwin" {
+			return frontendSignals{
+				paths:     []string{"/Library/Input Methods/Fcitx5.app", filepath.Join(home, "Library", "Input Methods", "Fcitx5.app")},
+				processes: []string{"Fcitx5"},
+			}
+		}
+		return frontendSignals{
+			binaries:  []string{"fcitx5"},
+			paths:     []string{"/usr/lib/fcitx5/librime.so", "/usr/lib64/fcitx5/librime.so", "/usr/lib/x86_64-linux-gnu/fcitx5/librime.so"},
+			processes: []string{"fcitx5"},
+		}
+	case "fcitx5-flatpak":
+		return frontendSignals{
+			paths: []string{
+				filepath.Join(home, ".local", "share", "flatpak", "app", "org.fcitx.Fcitx5"),
+				"/var/lib/flatpak/app/org.fcitx.Fcitx5",
+			},
+			processes: []string{"fcitx5"},
+			flatpak:   true,
+		}
+	case "fcitx":
+		return frontendSignals{
+			binaries:  []string{"fcitx"},
+			paths:     []string{"/usr/lib/fcitx/fcitx-rime.so", "/usr/lib64/fcitx/fcitx-rime.so", "/usr/lib/x86_64-linux-gnu/fcitx/fcitx-rime.so"},
+			processes: []string{"fcitx"},
+		}
+	case "squirrel":
+		return frontendSignals{
+			paths:     []string{"/Library/Input Methods/Squirrel.app", filepath.Join(home, "Library", "Input Methods", "Squirrel.app")},
+			processes: []string{"Squirrel"},
+		}
+	}
+	return frontendSignals{}
+}
+
+// Detect 返回检测到的前端，按可能性从高到低排列；没有任何依据的前端不会出现在结果中。
+// Windows 只有小狼毫，直接返回注册表中的目录
+func (d *Detector) Detect() ([]TargetCandidate, error) {
+	candidates, err := targetCandidates(d.OS, d.Getenv)
+	if err != nil || d.OS == "Windows_NT" {
+		return candidates, err
+	}
+
+	processes, err := d.Processes()
+	if err != nil {
+		// 无法列出进程时仍可根据目录和程序判断
+		processes = nil
+	}
+
+	var detected []TargetCandidate
+	for _, candidate := range candidates {
+		signals := d.signals(candidate.Frontend)
+		if process := findProcess(processes, signals); process != "" {
+			candidate.Score += scoreProcess
+			candidate.Reasons = append(candidate.Reasons, "正在运行 "+process)
+		}
+		if d.Exists(candidate.Dir) {
+			candidate.Score += scoreUserDir
+			candidate.Reasons = append(candidate.Reasons, "已有配置目录")
+		}
+		if installed := d.findInstalled(signals); installed != "" {
+			candidate.Score += scoreInstalled
+			candidate.Reasons = append(candidate.Reasons, "已安装 "+installed)
+		}
+		if candidate.Score > 0 {
+			detected = append(detected, candidate)
+		}
+	}
+	sort.SliceStable(detected, func(i, j int) bool {
+		return detected[i].Score > detected[j].Score
+	})
+	return detected, nil
+}
+
+func findProcess(processes []Process, signals frontendSignals) string {
+	for _, process := range processes {
+		if process.Flatpak != signals.flatpak {
+			continue
+		}
+		for _, name := range signals.processes {
+			if process.Name == name {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+func (d *Detector) findInstalled(signals frontendSignals) string {
+	for _, binary := range signals.binaries {
+		if _, err := d.LookPath(binary); err == nil {
+			return binary
+		}
+	}
+	for _, path := range signals.paths {
+		if d.Exists(path) {
+			return path
+		}
+	}
+	return ""
+}
+
+// listProcesses 列出当前运行的进程：Linux 读取 /proc，macOS 使用 ps
+func listProcesses() ([]Process, error) {
+	switch DetectOS() {
+	case "Linux":
+		return linuxProcesses("/proc")
+	case "Darwin":
+		output, err := exec.Command("ps", "-axco", "comm=").Output()
+		if err != nil {
+			return nil, err
+		}
+		var processes []Process
+		for _, line := range strings.Split(string(output), "\n") {
+			if name := strings.TrimSpace(line); name != "" {
+				processes = append(processes, Process{Name: name})
+			}
+		}
+		return processes, nil
+	}
+	return nil, nil
+}
+
+// linuxProcesses 读取 /proc/<pid>/comm；Flatpak 沙盒中的进程根目录下有 .flatpak-info
+func linuxProcesses(procDir string) ([]Process, error) {
+	entries, err := os.ReadDir(procDir)
+	if err != nil {
+		return nil, err
+	}
+	var processes []Process
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err != nil {
+			continue
+		}
+		comm, err := os.ReadFile(filepath.Join(procDir, entry.Name(), "comm"))
+		if err != nil {
+			continue
+		}
+		_, err = os.Stat(filepath.Join(procDir, entry.Name(), "root", ".flatpak-info"))
+		processes = append(processes, Process{Name: strings.TrimSpace(string(comm)), Flatpak: err == nil})
+	}
+	return processes, nil
+}
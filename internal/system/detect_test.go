@@ -0,0 +1,104 @@
+package system
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// fakeDetector 返回只存在 paths、PATH 中只有 binaries、正在运行 processes 的 Linux 环境
+func fakeDetector(paths, binaries []string, processes []Process) *Detector {
+	env := map[string]string{"HOME": "/home/u", "XDG_DATA_HOME": "/xdg/data"}
+	return &Detector{
+		OS:     "Linux",
+		Getenv: func(key string) string { return env[key] },
+		Exists: func(path string) bool {
+			for _, p := range paths {
+				if p == path {
+					return true
+				}
+			}
+			return false
+		},
+		LookPath: func(file string) (string, error) {
+			for _, b := range binaries {
+				if b == file {
+					return "/usr/bin/" + file, nil
+				}
+			}
+			return "", errors.New("not found")
+		},
+		Processes: func() ([]Process, error) { return processes, nil },
+	}
+}
+
+func frontends(candidates []TargetCandidate) []string {
+	var names []string
+	for _, candidate := range candidates {
+		names = append(names, candidate.Frontend)
+	}
+	return names
+}
+
+func TestDetectRanksRunningFrontendFirst(t *testing.T) {
+	d := fakeDetector(
+		[]string{"/home/u/.config/ibus/rime", "/xdg/data/fcitx5/rime"},
+		[]string{"fcitx5"},
+		[]Process{{Name: "fcitx5"}},
+	)
+	detected, err := d.Detect()
+	if err != nil {
+		t.Fatalf("Detect returned error: %v", err)
+	}
+	if got, want := frontends(detected), []string{"fcitx5", "ibus"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("detected = %v; want %v", got, want)
+	}
+	if detected[0].Score != scoreProcess+scoreUserDir+scoreInstalled || len(detected[0].Reasons) != 3 {
+		t.Fatalf("fcitx5 = %+v", detected[0])
+	}
+}
+
+func TestDetectDistinguishesFlatpakProcess(t *testing.T) {
+	d := fakeDetector(nil, nil, []Process{{Name: "fcitx5", Flatpak: true}, {Name: "fcitx"}})
+	detected, err := d.Detect()
+	if err != nil {
+		t.Fatalf("Detect returned error: %v", err)
+	}
+	if got, want := frontends(detected), []string{"fcitx5-flatpak", "fcitx"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("detected = %v; want %v", got, want)
+	}
+}
+
+func TestDetectNothing(t *testing.T) {
+	detected, err := fakeDetector(nil, nil, nil).Detect()
+	if err != nil || len(detected) != 0 {
+		t.Fatalf("Detect = %+v, %v; want nothing", detected, err)
+	}
+}
+
+func TestLinuxProcesses(t *testing.T) {
+	procDir := t.TempDir()
+	write := func(path, data string) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(procDir, "12", "comm"), "ibus-engine-rime\n")
+	write(filepath.Join(procDir, "34", "comm"), "fcitx5\n")
+	write(filepath.Join(procDir, "34", "root", ".flatpak-info"), "[Application]\n")
+	write(filepath.Join(procDir, "self", "comm"), "test\n")
+
+	processes, err := linuxProcesses(procDir)
+	if err != nil {
+		t.Fatalf("linuxProcesses returned error: %v", err)
+	}
+	want := []Process{{Name: "ibus-engine-rime"}, {Name: "fcitx5", Flatpak: true}}
+	if !reflect.DeepEqual(processes, want) {
+		t.Fatalf("processes = %+v; want %+v", processes, want)
+	}
+}
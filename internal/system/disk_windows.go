@@ -0,0 +1,18 @@
+//go:build windows
+
+package system
+
+import "golang.org/x/sys/windows"
+
+// FreeDiskSpace 返回路径所在磁盘对当前用户可用的剩余空间（字节）
+func FreeDiskSpace(path string) (uint64, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	var freeBytes uint64
+	if err := windows.GetDiskFreeSpaceEx(pathPtr, &freeBytes, nil, nil); err != nil {
+		return 0, err
+	}
+	return freeBytes, nil
+}
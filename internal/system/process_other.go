@@ -0,0 +1,22 @@
+//go:build !windows
+
+package system
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// ProcessExists 判断指定 pid 的进程是否仍在运行
+func ProcessExists(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
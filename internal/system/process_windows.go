@@ -0,0 +1,29 @@
+//go:build windows
+
+package system
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// stillActive GetExitCodeProcess 对仍在运行的进程返回的退出码
+const stillActive = 259
+
+// ProcessExists 判断指定 pid 的进程是否仍在运行
+func ProcessExists(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		// 无权限访问说明进程存在
+		return err == windows.ERROR_ACCESS_DENIED
+	}
+	defer windows.CloseHandle(handle)
+
+	var exitCode uint32
+	if err := windows.GetExitCodeProcess(handle, &exitCode); err != nil {
+		return false
+	}
+	return exitCode == stillActive
+}
@@ -1,12 +1,13 @@
 package system
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 )
 
@@ -23,98 +24,152 @@ func DetectOS() string {
 	return "Unknown"
 }
 
-// GetTargetDir 根据系统判断目标目录
-func GetTargetDir() string {
-	switch DetectOS() {
+// ErrInvalidChoice 选择的目录编号无效
+var ErrInvalidChoice = errors.New("无效选择")
+
+// TargetCandidate 候选的 Rime 用户目录
+type TargetCandidate struct {
+	// Label 显示名称，如 iBus、鼠须管
+	Label string `json:"label"`
+	// Frontend 输入法前端，如 ibus、fcitx5、squirrel
+	Frontend string `json:"frontend"`
+	Dir      string `json:"dir"`
+	// Score 自动检测的可能性得分，越高越可能是正在使用的前端
+	Score int `json:"score,omitempty"`
+	// Reasons 检测到该前端的依据，如 正在运行、已有配置目录
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// RimeUserDir 返回输入法前端默认的 Rime 用户目录，未知前端返回空字符串。
+// Linux 前端遵循 XDG_CONFIG_HOME、XDG_DATA_HOME，小狼毫优先使用注册表中的设置
+func RimeUserDir(frontend string) string {
+	return rimeUserDir(frontend, os.Getenv)
+}
+
+func rimeUserDir(frontend string, getenv func(string) string) string {
+	home := getenv("HOME")
+	configHome := getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(home, ".config")
+	}
+	dataHome := getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+
+	switch frontend {
+	case "ibus":
+		return filepath.Join(configHome, "ibus", "rime")
+	case "fcitx5":
+		return filepath.Join(dataHome, "fcitx5", "rime")
+	case "fcitx5-flatpak":
+		// Flatpak 沙盒内的数据目录固定位于 ~/.var/app/<应用 ID>
+		return filepath.Join(home, ".var", "app", "org.fcitx.Fcitx5", "data", "fcitx5", "rime")
+	case "fcitx":
+		return filepath.Join(configHome, "fcitx", "rime")
+	case "squirrel":
+		return filepath.Join(home, "Library", "Rime")
+	case "weasel":
+		dir, _ := WindowsTargetDir()
+		return dir
+	}
+	return ""
+}
+
+// TargetCandidates 返回当前系统可选的 Rime 用户目录，第一个为默认选择
+func TargetCandidates() ([]TargetCandidate, error) {
+	return targetCandidates(DetectOS(), os.Getenv)
+}
+
+func targetCandidates(osName string, getenv func(string) string) ([]TargetCandidate, error) {
+	var candidates []TargetCandidate
+	add := func(label, frontend string) {
+		candidates = append(candidates, TargetCandidate{Label: label, Frontend: frontend, Dir: rimeUserDir(frontend, getenv)})
+	}
+	// 候选顺序同时是检测结果得分相同时的排序依据
+	switch osName {
 	case "Windows_NT":
-		return GetWindowsTargetDir()
+		add("小狼毫", "weasel")
 	case "Linux":
-		return GetLinuxTargetDir()
+		add("iBus", "ibus")
+		add("Fcitx5", "fcitx5")
+		add("Fcitx5-Flatpak", "fcitx5-flatpak")
+		add("Fcitx4", "fcitx")
 	case "Darwin":
-		return GetDarwinTargetDir()
+		add("鼠须管", "squirrel")
+		add("小企鹅", "fcitx5")
+	default:
+		return nil, fmt.Errorf("不支持的操作系统: %s", osName)
 	}
-	return "Unknown"
+	return candidates, nil
 }
 
-// GetWindowsTargetDir Windows系统目标目录获取
-func GetWindowsTargetDir() string {
-	// 首先尝试从注册表读取Rime用户目录
-	if rimeDir, err := getRimeUserDirFromRegistry(); err == nil {
-		if rimeDir == "" {
-			fmt.Println("从注册表获取到的Rime用户目录为空，使用默认目录:", filepath.Join(os.Getenv("APPDATA"), "Rime"))
-			return filepath.Join(os.Getenv("APPDATA"), "Rime")
-		}
-		fmt.Println("从注册表获取到的Rime用户目录:", rimeDir)
-		return rimeDir
-	} else {
-		fmt.Println("从注册表读取Rime目录失败: ", err)
-		fmt.Println("使用默认目录...")
+// ChooseTarget 按输入的编号（从 1 开始）选择候选目录，输入为空时选择第一个
+func ChooseTarget(candidates []TargetCandidate, choice string) (TargetCandidate, error) {
+	choice = strings.TrimSpace(choice)
+	if len(candidates) == 0 {
+		return TargetCandidate{}, fmt.Errorf("没有可选的 Rime 用户目录")
+	}
+	if choice == "" {
+		return candidates[0], nil
+	}
+	index, err := strconv.Atoi(choice)
+	if err != nil || index < 1 || index > len(candidates) {
+		return TargetCandidate{}, fmt.Errorf("%w: %s", ErrInvalidChoice, choice)
 	}
-	// 如果注册表读取失败，回退到默认目录
-	return filepath.Join(os.Getenv("APPDATA"), "Rime")
+	return candidates[index-1], nil
 }
 
-// GetLinuxTargetDir Linux系统目标目录获取
-func GetLinuxTargetDir() string {
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Println("\n==============================")
-	fmt.Println(" 请选择 Rime 配置目录类型 ")
-	fmt.Println("==============================")
-	fmt.Println("1️⃣  iBus")
-	fmt.Println("2️⃣  Fcitx5")
-	fmt.Println("3️⃣  Fcitx5-Flatpak")
-	fmt.Println("------------------------------")
-	fmt.Print("请输入选项（1/2/3）：")
-	choice, _ := reader.ReadString('\n')
+// ChooseTargets 按输入选择多个候选目录：编号以逗号或空格分隔，a 选择全部，输入为空时选择第一个
+func ChooseTargets(candidates []TargetCandidate, choice string) ([]TargetCandidate, error) {
 	choice = strings.TrimSpace(choice)
+	if strings.EqualFold(choice, "a") {
+		return candidates, nil
+	}
+	fields := strings.FieldsFunc(choice, func(r rune) bool { return r == ',' || r == ' ' || r == '，' })
+	if len(fields) == 0 {
+		fields = []string{""}
+	}
 
-	switch choice {
-	case "1", "":
-		targetDir := filepath.Join(os.Getenv("HOME"), ".config", "ibus", "rime")
-		fmt.Println("目标地址: ", targetDir)
-		return targetDir
-	case "2":
-		targetDir := filepath.Join(os.Getenv("HOME"), ".local", "share", "fcitx5", "rime")
-		fmt.Println("目标地址: ", targetDir)
-		return targetDir
-	case "3":
-		targetDir := filepath.Join(os.Getenv("HOME"), ".var", "app", "org.fcitx.Fcitx5", "data", "fcitx5", "rime")
-		fmt.Println("目标地址: ", targetDir)
-		return targetDir
-	default:
-		fmt.Println("无效选择，程序已退出。")
-		os.Exit(1)
+	var chosen []TargetCandidate
+	seen := make(map[string]bool)
+	for _, field := range fields {
+		candidate, err := ChooseTarget(candidates, field)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[candidate.Dir] {
+			seen[candidate.Dir] = true
+			chosen = append(chosen, candidate)
+		}
 	}
-	return ""
+	return chosen, nil
 }
 
-// GetDarwinTargetDir Darwin系统目标目录获取
-func GetDarwinTargetDir() string {
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Println("\n==============================")
-	fmt.Println(" 请选择 Rime 配置目录类型 ")
-	fmt.Println("==============================")
-	fmt.Println("1️⃣  鼠须管")
-	fmt.Println("2️⃣  小企鹅")
-	fmt.Println("------------------------------")
-	fmt.Print("请输入选项（1/2）：")
-	choice, _ := reader.ReadString('\n')
-	choice = strings.TrimSpace(choice)
+// WindowsTargetDir 返回小狼毫的 Rime 用户目录：优先读取注册表，失败或为空时返回 %APPDATA%\Rime，
+// 同时返回未使用注册表的原因
+func WindowsTargetDir() (string, error) {
+	defaultDir := filepath.Join(os.Getenv("APPDATA"), "Rime")
+	rimeDir, err := getRimeUserDirFromRegistry()
+	if err != nil {
+		return defaultDir, fmt.Errorf("从注册表读取Rime目录失败: %v", err)
+	}
+	if rimeDir == "" {
+		return defaultDir, fmt.Errorf("从注册表获取到的Rime用户目录为空")
+	}
+	return rimeDir, nil
+}
 
-	switch choice {
-	case "1", "":
-		targetDir := filepath.Join(os.Getenv("HOME"), "Library", "Rime")
-		fmt.Println("目标地址: ", targetDir)
-		return targetDir
-	case "2":
-		targetDir := filepath.Join(os.Getenv("HOME"), ".local", "share", "fcitx5", "rime")
-		fmt.Println("目标地址: ", targetDir)
-		return targetDir
-	default:
-		fmt.Println("无效选择，程序已退出。")
-		os.Exit(1)
+// GetWindowsTargetDir Windows系统目标目录获取，输出目录来源
+func GetWindowsTargetDir() string {
+	rimeDir, err := WindowsTargetDir()
+	if err != nil {
+		fmt.Println(err)
+		fmt.Println("使用默认目录:", rimeDir)
+		return rimeDir
 	}
-	return ""
+	fmt.Println("从注册表获取到的Rime用户目录:", rimeDir)
+	return rimeDir
 }
 
 // OpenUrlBrowser 使用默认浏览器打开URL
@@ -155,6 +210,18 @@ func OpenFolder(path string) {
 	}
 }
 
+// AppConfigDir 返回本工具的配置与状态目录，可通过 OH_MY_RIME_CONFIG_DIR 环境变量覆盖
+func AppConfigDir() string {
+	if dir := os.Getenv("OH_MY_RIME_CONFIG_DIR"); dir != "" {
+		return ExpandHomeDir(dir)
+	}
+	base, err := os.UserConfigDir()
+	if err != nil {
+		base = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+	return filepath.Join(base, "oh-my-rime")
+}
+
 // ExpandHomeDir 展开路径中的 ~ 为实际的用户主目录
 func ExpandHomeDir(path string) string {
 	if strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "~\\") {
@@ -0,0 +1,92 @@
+package system
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTargetCandidates(t *testing.T) {
+	env := map[string]string{"HOME": "/home/u", "XDG_CONFIG_HOME": "/xdg/config"}
+	candidates, err := targetCandidates("Linux", func(key string) string { return env[key] })
+	if err != nil {
+		t.Fatalf("targetCandidates returned error: %v", err)
+	}
+	want := map[string]string{
+		"ibus":           filepath.Join("/xdg/config", "ibus", "rime"),
+		"fcitx5":         filepath.Join("/home/u", ".local", "share", "fcitx5", "rime"),
+		"fcitx5-flatpak": filepath.Join("/home/u", ".var", "app", "org.fcitx.Fcitx5", "data", "fcitx5", "rime"),
+		"fcitx":          filepath.Join("/xdg/config", "fcitx", "rime"),
+	}
+	if len(candidates) != len(want) {
+		t.Fatalf("candidates = %+v; want %d entries", candidates, len(want))
+	}
+	for _, candidate := range candidates {
+		if candidate.Dir != want[candidate.Frontend] {
+			t.Errorf("%s dir = %q; want %q", candidate.Frontend, candidate.Dir, want[candidate.Frontend])
+		}
+	}
+
+	if _, err := targetCandidates("Plan9", os.Getenv); err == nil {
+		t.Fatal("targetCandidates accepted an unknown OS")
+	}
+}
+
+func TestChooseTarget(t *testing.T) {
+	candidates, err := targetCandidates("Darwin", os.Getenv)
+	if err != nil {
+		t.Fatalf("targetCandidates returned error: %v", err)
+	}
+
+	tests := map[string]string{"": "squirrel", "1": "squirrel", " 2\n": "fcitx5"}
+	for choice, want := range tests {
+		candidate, err := ChooseTarget(candidates, choice)
+		if err != nil || candidate.Frontend != want {
+			t.Errorf("ChooseTarget(%q) = %+v, %v; want %s", choice, candidate, err, want)
+		}
+	}
+	for _, choice := range []string{"0", "3", "abc"} {
+		if _, err := ChooseTarget(candidates, choice); !errors.Is(err, ErrInvalidChoice) {
+			t.Errorf("ChooseTarget(%q) error = %v; want ErrInvalidChoice", choice, err)
+		}
+	}
+}
+
+func TestChooseTargets(t *testing.T) {
+	candidates, err := targetCandidates("Linux", os.Getenv)
+	if err != nil {
+		t.Fatalf("targetCandidates returned error: %v", err)
+	}
+	tests := map[string][]string{
+		"":      {"ibus"},
+		"2, 1":  {"fcitx5", "ibus"},
+		"1，1 3": {"ibus", "fcitx5-flatpak"},
+		"a":     {"ibus", "fcitx5", "fcitx5-flatpak", "fcitx"},
+	}
+	for choice, want := range tests {
+		chosen, err := ChooseTargets(candidates, choice)
+		if err != nil {
+			t.Errorf("ChooseTargets(%q) returned error: %v", choice, err)
+			continue
+		}
+		var got []string
+		for _, candidate := range chosen {
+			got = append(got, candidate.Frontend)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ChooseTargets(%q) = %v; want %v", choice, got, want)
+		}
+	}
+	if _, err := ChooseTargets(candidates, "1,9"); !errors.Is(err, ErrInvalidChoice) {
+		t.Errorf("ChooseTargets(1,9) error = %v; want ErrInvalidChoice", err)
+	}
+}
+
+func TestWindowsTargetDirFallsBackToAppData(t *testing.T) {
+	t.Setenv("APPDATA", "/appdata")
+	if dir, err := WindowsTargetDir(); err != nil && dir != filepath.Join("/appdata", "Rime") {
+		t.Fatalf("WindowsTargetDir = %q, %v; want default dir with reason", dir, err)
+	}
+}
@@ -0,0 +1,109 @@
+package updater
+
+import (
+	"archive/zip"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// NameCollision 在大小写不敏感或 Unicode 规范化的文件系统上会互相覆盖的一组条目
+type NameCollision struct {
+	// Key 折叠大小写并规范化后的路径
+	Key string
+	// Names zip 中的原始条目名，按出现顺序排列
+	Names []string
+}
+
+func (c NameCollision) String() string {
+	return strings.Join(c.Names, " ⇔ ")
+}
+
+// FindNameCollisions 找出折叠大小写并做 Unicode 规范化（NFC/NFD）后相同的条目名。
+// 仅包含目录的冲突不会覆盖文件，不予报告
+func FindNameCollisions(names []string) []NameCollision {
+	fold := cases.Fold()
+	groups := make(map[string][]string)
+	var keys []string
+	for _, name := range names {
+		trimmed := strings.TrimSuffix(name, "/")
+		if trimmed == "" {
+			continue
+		}
+		key := collisionKey(fold, trimmed)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], name)
+	}
+
+	var collisions []NameCollision
+	for _, key := range keys {
+		distinct := distinctNames(groups[key])
+		if len(distinct) < 2 || allDirs(distinct) {
+			continue
+		}
+		collisions = append(collisions, NameCollision{Key: key, Names: distinct})
+	}
+	sort.Slice(collisions, func(i, j int) bool {
+		return collisions[i].Key < collisions[j].Key
+	})
+	return collisions
+}
+
+func collisionKey(fold cases.Caser, name string) string {
+	// 先统一为 NFD 再折叠大小写，最后转换为 NFC，使两种规范化形式得到相同的键
+	return norm.NFC.String(fold.String(norm.NFD.String(name)))
+}
+
+func distinctNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	var distinct []string
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			distinct = append(distinct, name)
+		}
+	}
+	return distinct
+}
+
+func allDirs(names []string) bool {
+	for _, name := range names {
+		if !strings.HasSuffix(name, "/") {
+			return false
+		}
+	}
+	return true
+}
+
+// checkNameCollisions 解压前检查条目名冲突。
+// macOS 与 Windows 的默认文件系统会互相覆盖，视为错误；其他系统仅给出警告
+func checkNameCollisions(files []*zip.File, allow bool) error {
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		names = append(names, file.Name)
+	}
+
+	collisions := FindNameCollisions(names)
+	if len(collisions) == 0 {
+		return nil
+	}
+
+	fmt.Println("⚠️  以下 zip 条目在大小写不敏感或 Unicode 规范化的文件系统上会互相覆盖:")
+	for _, collision := range collisions {
+		fmt.Printf("  %s\n", collision)
+	}
+	if allow || !caseInsensitiveOS() {
+		return nil
+	}
+	return invalidPackage("zip 中有 %d 组文件名冲突，已取消解压", len(collisions))
+}
+
+func caseInsensitiveOS() bool {
+	return runtime.GOOS == "darwin" || runtime.GOOS == "windows"
+}
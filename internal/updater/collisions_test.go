@@ -0,0 +1,71 @@
+package updater
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindNameCollisions(t *testing.T) {
+	tests := []struct {
+		name  string
+		names []string
+		want  [][]string
+	}{
+		{
+			name:  "case",
+			names: []string{"Luna.schema.yaml", "luna.schema.yaml", "default.yaml"},
+			want:  [][]string{{"Luna.schema.yaml", "luna.schema.yaml"}},
+		},
+		{
+			// “é” 的 NFC 与 NFD 形式
+			name:  "normalization",
+			names: []string{"caf\u00e9.txt", "cafe\u0301.txt"},
+			want:  [][]string{{"caf\u00e9.txt", "cafe\u0301.txt"}},
+		},
+		{
+			// 韩文音节在 APFS 上会被分解为字母
+			name:  "hangul",
+			names: []string{"\ud55c.dict.yaml", "\u1112\u1161\u11ab.dict.yaml"},
+			want:  [][]string{{"\ud55c.dict.yaml", "\u1112\u1161\u11ab.dict.yaml"}},
+		},
+		{
+			name:  "nested path",
+			names: []string{"Lua/Helper.lua", "lua/helper.lua", "lua/other.lua"},
+			want:  [][]string{{"Lua/Helper.lua", "lua/helper.lua"}},
+		},
+		{
+			name:  "file and directory",
+			names: []string{"dicts/", "Dicts"},
+			want:  [][]string{{"dicts/", "Dicts"}},
+		},
+		{
+			name:  "directories only",
+			names: []string{"Lua/", "lua/"},
+		},
+		{
+			name:  "chinese names are distinct",
+			names: []string{"薄荷.schema.yaml", "薄荷拼音.schema.yaml", "default.yaml"},
+		},
+		{
+			name:  "exact duplicate",
+			names: []string{"default.yaml", "default.yaml"},
+		},
+		{
+			name:  "case and normalization together",
+			names: []string{"CAF\u00c9.yaml", "cafe\u0301.yaml", "Caf\u00e9.yaml"},
+			want:  [][]string{{"CAF\u00c9.yaml", "cafe\u0301.yaml", "Caf\u00e9.yaml"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][]string
+			for _, collision := range FindNameCollisions(tt.names) {
+				got = append(got, collision.Names)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("FindNameCollisions(%q) = %q; want %q", tt.names, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package updater
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrUnsupportedCustomSource 自定义更新的地址既不是 zip 也不是 gram 文件
+var ErrUnsupportedCustomSource = errors.New("不支持的文件类型，请提供 zip 或 gram 文件的 URL")
+
+// CustomOperation 根据自定义更新地址的扩展名判断更新类型：.zip 更新主方案，.gram 更新模型。
+// 命令行与图形界面共用，保证同一地址的处理方式一致
+func CustomOperation(source string) (string, error) {
+	lower := strings.ToLower(source)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return OperationMainScheme, nil
+	case strings.HasSuffix(lower, ".gram"):
+		return OperationModel, nil
+	}
+	return "", ErrUnsupportedCustomSource
+}
@@ -0,0 +1,25 @@
+package updater
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCustomOperation(t *testing.T) {
+	tests := map[string]string{
+		"https://example.com/oh-my-rime.ZIP":    OperationMainScheme,
+		"https://example.com/wanxiang-lts.gram": OperationModel,
+		"https://example.com/releases/latest":   "",
+		"https://example.com/wanxiang.gram.bak": "",
+	}
+	for source, want := range tests {
+		got, err := CustomOperation(source)
+		if want == "" {
+			if !errors.Is(err, ErrUnsupportedCustomSource) {
+				t.Errorf("CustomOperation(%s) = %q, %v; want ErrUnsupportedCustomSource", source, got, err)
+			}
+		} else if err != nil || got != want {
+			t.Errorf("CustomOperation(%s) = %q, %v; want %q", source, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package updater
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// DictInfo 词库包中的一个文件
+type DictInfo struct {
+	// Path 相对于 dicts 目录的路径
+	Path string `json:"path"`
+	// Name 与 Version 来自 .dict.yaml 文件头
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+	// Size 解压后的大小
+	Size int64 `json:"size"`
+	// Entries 词条数（文件头之后的非注释行）
+	Entries int `json:"entries"`
+}
+
+// DictSelection 词库选择规则，匹配相对于 dicts 目录的路径或文件名
+type DictSelection struct {
+	// Include 为空时表示全部安装
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// Matches 判断词库文件是否应被安装
+func (s *DictSelection) Matches(relPath string) bool {
+	if s == nil {
+		return true
+	}
+	if len(s.Include) > 0 && !matchAnyGlob(s.Include, relPath) {
+		return false
+	}
+	return !matchAnyGlob(s.Exclude, relPath)
+}
+
+// IsEmpty 判断是否未设置任何规则（即全部安装）
+func (s *DictSelection) IsEmpty() bool {
+	return s == nil || (len(s.Include) == 0 && len(s.Exclude) == 0)
+}
+
+func matchAnyGlob(patterns []string, relPath string) bool {
+	relPath = filepath.ToSlash(relPath)
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if ok, _ := path.Match(pattern, relPath); ok {
+			return true
+		}
+		// 不含目录的规则同时匹配文件名
+		if !strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(pattern, path.Base(relPath)); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ValidateGlobs 检查用户输入的匹配规则
+func ValidateGlobs(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("匹配规则无效 %q: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// IsDictEntry 判断 zip 条目是否属于 dicts 目录，用于按需下载词库
+func IsDictEntry(name string) bool {
+	return strings.HasPrefix(name, "dicts/")
+}
+
+// ListDicts 列出 zip 中 dicts 目录下的词库文件，encodingName 为未标记 UTF-8 的文件名编码，空值或 auto 时自动识别
+func ListDicts(rimeZip []byte, encodingName string) ([]DictInfo, error) {
+	if len(rimeZip) == 0 {
+		return nil, invalidPackage("zip数据无效")
+	}
+	zipReader, err := zip.NewReader(bytes.NewReader(rimeZip), int64(len(rimeZip)))
+	if err != nil {
+		return nil, invalidPackage("读取zip文件失败: %v", err)
+	}
+	if err := decodeNames(zipReader.File, encodingName); err != nil {
+		return nil, err
+	}
+
+	var dicts []DictInfo
+	for _, file := range zipReader.File {
+		if !strings.HasPrefix(file.Name, "dicts/") || file.FileInfo().IsDir() || isSymlinkEntry(file) {
+			continue
+		}
+		info := DictInfo{
+			Path: strings.TrimPrefix(file.Name, "dicts/"),
+			Size: int64(file.UncompressedSize64),
+		}
+		if strings.HasSuffix(file.Name, ".dict.yaml") {
+			if err := readDictHeader(file, &info); err != nil {
+				return nil, fmt.Errorf("读取词库 %s 失败: %v", info.Path, err)
+			}
+		}
+		dicts = append(dicts, info)
+	}
+	return dicts, nil
+}
+
+// readDictHeader 解析 .dict.yaml 的 YAML 文件头（--- 与 ... 之间），并统计其后的词条数
+func readDictHeader(file *zip.File, info *DictInfo) error {
+	rc, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	scanner := bufio.NewScanner(rc)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	inHeader := true
+	for scanner.Scan() {
+		line := scanner.Text()
+		if inHeader {
+			trimmed := strings.TrimSpace(line)
+			if trimmed == "..." {
+				inHeader = false
+				continue
+			}
+			// 只读取顶层字段
+			if strings.HasPrefix(line, "name:") {
+				info.Name = unquoteYAML(strings.TrimPrefix(line, "name:"))
+			} else if strings.HasPrefix(line, "version:") {
+				info.Version = unquoteYAML(strings.TrimPrefix(line, "version:"))
+			}
+			continue
+		}
+		if trimmed := strings.TrimSpace(line); trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			info.Entries++
+		}
+	}
+	return scanner.Err()
+}
+
+func unquoteYAML(value string) string {
+	value = strings.TrimSpace(value)
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return strings.Trim(value, `"'`)
+}
+
+// dictSelectionPath 词库选择保存在目标目录下，随目标目录一起备份
+func dictSelectionPath(targetDir string) string {
+	return filepath.Join(stateDir(targetDir), "dict-selection.json")
+}
+
+// LoadDictSelection 读取目标目录上次保存的词库选择，未保存时返回 nil
+func LoadDictSelection(targetDir string) (*DictSelection, error) {
+	data, err := os.ReadFile(dictSelectionPath(targetDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var selection DictSelection
+	if err := json.Unmarshal(data, &selection); err != nil {
+		return nil, fmt.Errorf("词库选择格式错误: %v", err)
+	}
+	return &selection, nil
+}
+
+// SaveDictSelection 保存目标目录的词库选择，选择为空时删除已保存的记录
+func SaveDictSelection(targetDir string, selection *DictSelection) error {
+	path := dictSelectionPath(targetDir)
+	if selection.IsEmpty() {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(selection, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// resolveDictSelection 优先使用本次指定的选择，否则沿用目标目录保存的选择
+func resolveDictSelection(targetDir string, opts Options) (*DictSelection, error) {
+	if opts.DictSelection != nil {
+		return opts.DictSelection, nil
+	}
+	return LoadDictSelection(targetDir)
+}
+
+// filterDicts 按词库选择过滤 dicts 目录下的文件，其他条目原样保留
+func filterDicts(files []*zip.File, selection *DictSelection) []*zip.File {
+	if selection.IsEmpty() {
+		return files
+	}
+
+	var kept []*zip.File
+	for _, file := range files {
+		relPath := strings.TrimPrefix(file.Name, "dicts/")
+		if relPath != file.Name && !file.FileInfo().IsDir() && !selection.Matches(relPath) {
+			fmt.Printf("跳过未选择的词库: %s\n", relPath)
+			continue
+		}
+		kept = append(kept, file)
+	}
+	return kept
+}
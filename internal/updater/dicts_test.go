@@ -0,0 +1,122 @@
+package updater
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+const testDictYAML = `# Rime dictionary
+---
+name: rime_mint.base
+version: "2024-05-01"  # 版本
+sort: by_weight
+use_preset_vocabulary: false
+...
+
+# 词条
+你好	ni hao	100
+世界	shi jie	90
+
+再见	zai jian
+`
+
+func TestListDictsReadsHeaders(t *testing.T) {
+	dicts, err := ListDicts(testZip(t,
+		zipEntry{name: "dicts/", mode: os.ModeDir},
+		zipEntry{name: "dicts/rime_mint.base.dict.yaml", body: testDictYAML},
+		zipEntry{name: "dicts/README.md", body: "readme"},
+		zipEntry{name: "default.yaml", body: "config_version: 1"},
+	), NameEncodingAuto)
+	if err != nil {
+		t.Fatalf("ListDicts returned error: %v", err)
+	}
+
+	want := []DictInfo{
+		{Path: "rime_mint.base.dict.yaml", Name: "rime_mint.base", Version: "2024-05-01", Size: int64(len(testDictYAML)), Entries: 3},
+		{Path: "README.md", Size: 6},
+	}
+	if !reflect.DeepEqual(dicts, want) {
+		t.Fatalf("ListDicts = %+v; want %+v", dicts, want)
+	}
+}
+
+func TestListDictsUsesNameEncoding(t *testing.T) {
+	dicts, err := ListDicts(legacyZip(t, traditionalchinese.Big5, "dicts/倉頡.txt"), "big5")
+	if err != nil {
+		t.Fatalf("ListDicts returned error: %v", err)
+	}
+	if len(dicts) != 1 || dicts[0].Path != "倉頡.txt" {
+		t.Fatalf("ListDicts = %+v; want 倉頡.txt", dicts)
+	}
+}
+
+func TestDictSelectionMatches(t *testing.T) {
+	selection := &DictSelection{Include: []string{"rime_mint.*"}, Exclude: []string{"*.ext.dict.yaml"}}
+
+	tests := map[string]bool{
+		"rime_mint.base.dict.yaml":        true,
+		"rime_mint.ext.dict.yaml":         false,
+		"wanxiang/rime_mint.ci.dict.yaml": true,
+		"other.dict.yaml":                 false,
+	}
+	for path, want := range tests {
+		if got := selection.Matches(path); got != want {
+			t.Errorf("Matches(%q) = %v; want %v", path, got, want)
+		}
+	}
+	if !(*DictSelection)(nil).Matches("anything") {
+		t.Error("nil selection should match everything")
+	}
+}
+
+func TestUpdateDictRemembersSelection(t *testing.T) {
+	targetDir := filepath.Join(t.TempDir(), "Rime")
+	rimeZip := testZip(t,
+		zipEntry{name: "dicts/base.dict.yaml", body: testDictYAML},
+		zipEntry{name: "dicts/ext.dict.yaml", body: testDictYAML},
+		zipEntry{name: "default.yaml", body: "config_version: 1"},
+	)
+
+	opts := Options{DictSelection: &DictSelection{Exclude: []string{"ext.dict.yaml"}}}
+	if err := UpdateDictWithOptions(rimeZip, targetDir, opts); err != nil {
+		t.Fatalf("UpdateDictWithOptions returned error: %v", err)
+	}
+	assertExists(t, filepath.Join(targetDir, "dicts", "base.dict.yaml"), true)
+	assertExists(t, filepath.Join(targetDir, "dicts", "ext.dict.yaml"), false)
+
+	saved, err := LoadDictSelection(targetDir)
+	if err != nil || saved == nil || !reflect.DeepEqual(saved.Exclude, []string{"ext.dict.yaml"}) {
+		t.Fatalf("LoadDictSelection = %+v, %v; want saved exclude", saved, err)
+	}
+
+	// 后续的词库更新和主方案更新沿用已保存的选择
+	if err := UpdateDict(rimeZip, targetDir); err != nil {
+		t.Fatalf("UpdateDict returned error: %v", err)
+	}
+	if err := UpdateMainScheme(rimeZip, targetDir); err != nil {
+		t.Fatalf("UpdateMainScheme returned error: %v", err)
+	}
+	assertExists(t, filepath.Join(targetDir, "dicts", "ext.dict.yaml"), false)
+	assertExists(t, filepath.Join(targetDir, "default.yaml"), true)
+
+	// 选择全部安装后清除保存的选择
+	if err := UpdateDictWithOptions(rimeZip, targetDir, Options{DictSelection: &DictSelection{}}); err != nil {
+		t.Fatalf("UpdateDictWithOptions returned error: %v", err)
+	}
+	assertExists(t, filepath.Join(targetDir, "dicts", "ext.dict.yaml"), true)
+	if saved, err := LoadDictSelection(targetDir); err != nil || saved != nil {
+		t.Fatalf("LoadDictSelection = %+v, %v; want nil", saved, err)
+	}
+}
+
+func assertExists(t *testing.T, path string, want bool) {
+	t.Helper()
+	_, err := os.Lstat(path)
+	if exists := err == nil; exists != want {
+		t.Fatalf("%s exists = %v (%v); want %v", path, exists, err, want)
+	}
+}
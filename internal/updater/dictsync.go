@@ -0,0 +1,91 @@
+package updater
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DictSyncMode 词库更新的同步方式
+type DictSyncMode string
+
+const (
+	// DictSyncMerge 只覆盖包中的词库，保留本地其他文件（默认）
+	DictSyncMerge DictSyncMode = "merge"
+	// DictSyncMirror 删除本次未安装的词库文件，使 dicts 目录与包一致
+	DictSyncMirror DictSyncMode = "mirror"
+)
+
+// DefaultDictKeepPatterns 镜像模式下默认保留的用户词库
+var DefaultDictKeepPatterns = []string{"*.custom.dict.yaml", "custom_*"}
+
+// ParseDictSyncMode 解析用户输入的同步方式，空值为 merge
+func ParseDictSyncMode(value string) (DictSyncMode, error) {
+	switch DictSyncMode(strings.ToLower(strings.TrimSpace(value))) {
+	case "", DictSyncMerge:
+		return DictSyncMerge, nil
+	case DictSyncMirror:
+		return DictSyncMirror, nil
+	}
+	return "", fmt.Errorf("不支持的词库同步方式: %s（可选 merge、mirror）", value)
+}
+
+func (o Options) dictKeepPatterns() []string {
+	if o.DictKeep != nil {
+		return o.DictKeep
+	}
+	return DefaultDictKeepPatterns
+}
+
+// mirrorDicts 删除 dicts 目录中本次未安装且不匹配保留规则的文件，并清理空目录。
+// keep 匹配相对于 dicts 目录的路径；protected 与 filterProtected 一致，匹配相对于目标目录的路径（dicts/...）
+func mirrorDicts(dictsDir string, installed map[string]bool, keep, protected []string) error {
+	var removeFiles, dirs []string
+	err := filepath.WalkDir(dictsDir, func(path string, d os.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if path == dictsDir {
+			return nil
+		}
+		if d.IsDir() {
+			dirs = append(dirs, path)
+			return nil
+		}
+
+		relPath, err := filepath.Rel(dictsDir, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		if installed[relPath] || matchAnyGlob(keep, relPath) || matchAnyGlob(protected, "dicts/"+relPath) {
+			return nil
+		}
+		removeFiles = append(removeFiles, path)
+		return nil
+	})
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, path := range removeFiles {
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("删除过期词库失败 %s: %v", path, err)
+		}
+		fmt.Printf("删除过期词库: %s\n", path)
+	}
+
+	// 由深到浅删除空目录
+	sort.Slice(dirs, func(i, j int) bool { return len(dirs[i]) > len(dirs[j]) })
+	for _, dir := range dirs {
+		if entries, err := os.ReadDir(dir); err == nil && len(entries) == 0 {
+			os.Remove(dir)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package updater
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeLocalDicts(t *testing.T, dictsDir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		path := filepath.Join(dictsDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("create dict dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("local"), 0644); err != nil {
+			t.Fatalf("write local dict: %v", err)
+		}
+	}
+}
+
+func TestUpdateDictMirrorKeepsProtectedDicts(t *testing.T) {
+	targetDir := filepath.Join(t.TempDir(), "Rime")
+	dictsDir := filepath.Join(targetDir, "dicts")
+	writeLocalDicts(t, dictsDir, "my.dict.yaml", "team/words.dict.yaml", "old.dict.yaml")
+
+	rimeZip := testZip(t,
+		zipEntry{name: "dicts/base.dict.yaml", body: testDictYAML},
+		zipEntry{name: "dicts/my.dict.yaml", body: testDictYAML},
+	)
+	opts := Options{
+		DictSync:  DictSyncMirror,
+		Protected: []string{"dicts/my.dict.yaml", "dicts/team/*"},
+	}
+	if err := UpdateDictWithOptions(rimeZip, targetDir, opts); err != nil {
+		t.Fatalf("UpdateDictWithOptions returned error: %v", err)
+	}
+
+	// 受保护的词库既不被包中的同名文件覆盖，也不会在镜像模式下被删除
+	if data, err := os.ReadFile(filepath.Join(dictsDir, "my.dict.yaml")); err != nil || string(data) != "local" {
+		t.Fatalf("protected dict = %q, %v; want local", data, err)
+	}
+	assertExists(t, filepath.Join(dictsDir, "team", "words.dict.yaml"), true)
+	assertExists(t, filepath.Join(dictsDir, "base.dict.yaml"), true)
+	assertExists(t, filepath.Join(dictsDir, "old.dict.yaml"), false)
+}
+
+func TestUpdateDictMergeKeepsLocalDicts(t *testing.T) {
+	targetDir := filepath.Join(t.TempDir(), "Rime")
+	dictsDir := filepath.Join(targetDir, "dicts")
+	writeLocalDicts(t, dictsDir, "old.dict.yaml")
+
+	if err := UpdateDict(testZip(t, zipEntry{name: "dicts/base.dict.yaml", body: testDictYAML}), targetDir); err != nil {
+		t.Fatalf("UpdateDict returned error: %v", err)
+	}
+	assertExists(t, filepath.Join(dictsDir, "old.dict.yaml"), true)
+	assertExists(t, filepath.Join(dictsDir, "base.dict.yaml"), true)
+}
+
+func TestUpdateDictMirrorRemovesStaleDicts(t *testing.T) {
+	targetDir := filepath.Join(t.TempDir(), "Rime")
+	dictsDir := filepath.Join(targetDir, "dicts")
+	writeLocalDicts(t, dictsDir,
+		"old.dict.yaml",
+		"renamed/stale.dict.yaml",
+		"my.custom.dict.yaml",
+		"team/company.dict.yaml",
+		"ext.dict.yaml",
+	)
+
+	rimeZip := testZip(t,
+		zipEntry{name: "dicts/", mode: os.ModeDir},
+		zipEntry{name: "dicts/base.dict.yaml", body: testDictYAML},
+		zipEntry{name: "dicts/ext.dict.yaml", body: testDictYAML},
+		zipEntry{name: "dicts/cn/", mode: os.ModeDir},
+		zipEntry{name: "dicts/cn/words.dict.yaml", body: testDictYAML},
+	)
+	opts := Options{
+		DictSync:      DictSyncMirror,
+		DictKeep:      append([]string{"team/*"}, DefaultDictKeepPatterns...),
+		DictSelection: &DictSelection{Exclude: []string{"ext.dict.yaml"}},
+	}
+	if err := UpdateDictWithOptions(rimeZip, targetDir, opts); err != nil {
+		t.Fatalf("UpdateDictWithOptions returned error: %v", err)
+	}
+
+	assertExists(t, filepath.Join(dictsDir, "base.dict.yaml"), true)
+	assertExists(t, filepath.Join(dictsDir, "cn", "words.dict.yaml"), true)
+	assertExists(t, filepath.Join(dictsDir, "my.custom.dict.yaml"), true)
+	assertExists(t, filepath.Join(dictsDir, "team", "company.dict.yaml"), true)
+	assertExists(t, filepath.Join(dictsDir, "old.dict.yaml"), false)
+	assertExists(t, filepath.Join(dictsDir, "renamed"), false)
+	// 未选择的词库同样被删除
+	assertExists(t, filepath.Join(dictsDir, "ext.dict.yaml"), false)
+}
+
+func TestParseDictSyncMode(t *testing.T) {
+	for input, want := range map[string]DictSyncMode{"": DictSyncMerge, "merge": DictSyncMerge, "Mirror": DictSyncMirror} {
+		if got, err := ParseDictSyncMode(input); err != nil || got != want {
+			t.Errorf("ParseDictSyncMode(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := ParseDictSyncMode("delete"); err == nil {
+		t.Error("ParseDictSyncMode(delete) returned nil; want error")
+	}
+}
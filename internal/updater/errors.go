@@ -0,0 +1,42 @@
+package updater
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidPackage 更新包未通过校验，如 zip 损坏、包含不安全路径或符号链接、文件名冲突
+	ErrInvalidPackage = errors.New("更新包无效")
+	// ErrRolledBack 更新失败，已恢复到更新前状态
+	ErrRolledBack = errors.New("已恢复到更新前状态")
+	// ErrRollbackFailed 更新失败且备份恢复失败，目标目录可能处于部分更新的状态
+	ErrRollbackFailed = errors.New("备份恢复失败")
+)
+
+// markedError 保留原错误的消息，同时可通过 errors.Is 匹配附加的哨兵错误
+type markedError struct {
+	err  error
+	mark error
+}
+
+func (e *markedError) Error() string   { return e.err.Error() }
+func (e *markedError) Unwrap() []error { return []error{e.err, e.mark} }
+
+// markError 为 err 附加哨兵错误 mark，不改变错误消息
+func markError(err, mark error) error {
+	if err == nil || errors.Is(err, mark) {
+		return err
+	}
+	return &markedError{err: err, mark: mark}
+}
+
+// invalidPackage 返回带有 ErrInvalidPackage 标记的错误
+func invalidPackage(format string, args ...interface{}) error {
+	return markError(fmt.Errorf(format, args...), ErrInvalidPackage)
+}
+
+// IsInvalidPackage 判断更新是否因为更新包本身无效而失败，重试同一个包不会成功
+func IsInvalidPackage(err error) bool {
+	return errors.Is(err, ErrInvalidPackage) || errors.Is(err, ErrInvalidModel) || errors.Is(err, ErrLimitExceeded)
+}
@@ -0,0 +1,27 @@
+package updater
+
+// 更新事件类型
+const (
+	EventBackupCreated  = "backup-created"
+	EventFileWritten    = "file-written"
+	EventRolledBack     = "rolled-back"
+	EventRollbackFailed = "rollback-failed"
+)
+
+// Event 更新过程中的事件，供机器可读的输出使用
+type Event struct {
+	Type string `json:"type"`
+	// Path 事件涉及的文件或目录
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// EventCallback 接收更新事件的回调函数
+type EventCallback func(event Event)
+
+// emit 发送更新事件，未设置回调时不执行任何操作
+func (o Options) emit(event Event) {
+	if o.Events != nil {
+		o.Events(event)
+	}
+}
@@ -0,0 +1,103 @@
+package updater
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"oh-my-rime-cli/internal/downloader"
+)
+
+// .gram 文件由 librime-octagram 生成，文件头为 grammar::Metadata（小端序）：
+//
+//	char     format[32]         "Rime::Grammar/1.0"，以 NUL 结尾
+//	uint32   db_checksum        校验和
+//	uint32   double_array_size  双数组的单元数，每个单元 4 字节
+//	int32    double_array       OffsetPtr，相对于该字段自身地址的偏移
+const (
+	gramFormatPrefix    = "Rime::Grammar/"
+	gramFormatMaxLength = 32
+	gramArraySizeOffset = gramFormatMaxLength + 4
+	gramArrayPtrOffset  = gramFormatMaxLength + 8
+	gramHeaderSize      = gramFormatMaxLength + 12
+	gramUnitSize        = 4
+	// 小于该大小的文件不可能是有效的语法模型
+	minModelSize = 1024
+)
+
+// ErrInvalidModel 模型文件结构无效
+var ErrInvalidModel = errors.New("模型文件无效")
+
+// ValidateModel 检查 .gram 文件的文件头、最小大小以及声明的数据长度与实际长度是否一致
+func ValidateModel(data []byte) error {
+	trimmed := bytes.TrimSpace(data[:min(len(data), 512)])
+	if bytes.HasPrefix(trimmed, []byte("<")) {
+		return fmt.Errorf("%w: 内容是 HTML/XML 页面，链接可能无效或需要权限认证", ErrInvalidModel)
+	}
+	if len(data) < minModelSize {
+		return fmt.Errorf("%w: 文件过小（%s）", ErrInvalidModel, downloader.FormatBytes(int64(len(data))))
+	}
+
+	declared, err := gramDeclaredSize(data)
+	if err != nil {
+		return err
+	}
+	if declared > int64(len(data)) {
+		return fmt.Errorf("%w: 文件不完整，声明 %s，实际 %s", ErrInvalidModel,
+			downloader.FormatBytes(declared), downloader.FormatBytes(int64(len(data))))
+	}
+	return nil
+}
+
+// gramDeclaredSize 根据文件头计算双数组结束的位置，即文件至少应有的长度
+func gramDeclaredSize(header []byte) (int64, error) {
+	if len(header) < gramHeaderSize {
+		return 0, fmt.Errorf("%w: 文件头不完整", ErrInvalidModel)
+	}
+	format := header[:gramFormatMaxLength]
+	end := bytes.IndexByte(format, 0)
+	if !bytes.HasPrefix(format, []byte(gramFormatPrefix)) || end < 0 {
+		return 0, fmt.Errorf("%w: 文件头不是 Rime 语法模型", ErrInvalidModel)
+	}
+
+	units := binary.LittleEndian.Uint32(header[gramArraySizeOffset:])
+	offset := int32(binary.LittleEndian.Uint32(header[gramArrayPtrOffset:]))
+	start := int64(gramArrayPtrOffset) + int64(offset)
+	if units == 0 || offset == 0 || start < gramHeaderSize {
+		return 0, fmt.Errorf("%w: %s 文件头中的数据位置无效", ErrInvalidModel, format[:end])
+	}
+	return start + int64(units)*gramUnitSize, nil
+}
+
+// writeFileAtomic 先写入同目录下的临时文件再重命名，写入失败时原文件保持不变
+func writeFileAtomic(path string, data []byte, perm os.FileMode, j *journal) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	// 中断后回滚时一并删除临时文件
+	j.record(tmpPath)
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpPath, perm)
+	}
+	if err == nil {
+		j.record(path)
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+	}
+	return err
+}
@@ -0,0 +1,100 @@
+package updater
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// wanxiangGramHeader 按 librime-octagram grammar.h 的 Metadata 布局构造的万象模型文件头，
+// 校验和与单元数为示例值：format 之后依次为 db_checksum、double_array_size（30000000 个单元）
+// 和指向紧随文件头的双数组的偏移 4。校验和被误当作单元数时会得到远超文件大小的声明长度
+var wanxiangGramHeader = []byte{
+	'R', 'i', 'm', 'e', ':', ':', 'G', 'r', 'a', 'm', 'm', 'a', 'r', '/', '1', '.',
+	'0', 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0x21, 0x6b, 0x3a, 0x8f, // db_checksum
+	0x80, 0xc3, 0xc9, 0x01, // double_array_size
+	0x04, 0x00, 0x00, 0x00, // double_array
+}
+
+// testGram 构造结构有效的 .gram 数据，payload 写在双数组区域开头
+func testGram(payload string) []byte {
+	size := gramHeaderSize + len(payload)
+	if size < minModelSize {
+		size = minModelSize
+	}
+	size += (gramUnitSize - size%gramUnitSize) % gramUnitSize
+
+	data := make([]byte, size)
+	copy(data, wanxiangGramHeader)
+	binary.LittleEndian.PutUint32(data[gramArraySizeOffset:], uint32((size-gramHeaderSize)/gramUnitSize))
+	copy(data[gramHeaderSize:], payload)
+	return data
+}
+
+func TestGramDeclaredSizeReadsMetadata(t *testing.T) {
+	declared, err := gramDeclaredSize(wanxiangGramHeader)
+	if err != nil {
+		t.Fatalf("gramDeclaredSize returned error: %v", err)
+	}
+	if want := int64(gramHeaderSize + 30000000*gramUnitSize); declared != want {
+		t.Fatalf("declared size = %d; want %d", declared, want)
+	}
+	if _, err := gramDeclaredSize(wanxiangGramHeader[:gramArrayPtrOffset]); !errors.Is(err, ErrInvalidModel) {
+		t.Fatalf("gramDeclaredSize(short header) = %v; want ErrInvalidModel", err)
+	}
+}
+
+func TestValidateModel(t *testing.T) {
+	if err := ValidateModel(testGram("model")); err != nil {
+		t.Fatalf("ValidateModel(valid) = %v", err)
+	}
+
+	badMagic := testGram("model")
+	copy(badMagic, "Rime::Table/4.0")
+	noTerminator := testGram("model")
+	copy(noTerminator, strings.Repeat("Rime::Grammar/", 3)[:gramFormatMaxLength])
+	badOffset := testGram("model")
+	binary.LittleEndian.PutUint32(badOffset[gramArrayPtrOffset:], 0)
+
+	tests := map[string][]byte{
+		"html":         []byte("\n<!DOCTYPE html><html>" + strings.Repeat(" ", 2048)),
+		"too small":    testGram("model")[:512],
+		"bad magic":    badMagic,
+		"unterminated": noTerminator,
+		"bad offset":   badOffset,
+		"truncated":    testGram(strings.Repeat("x", 4096))[:3000],
+	}
+	for name, data := range tests {
+		if err := ValidateModel(data); !errors.Is(err, ErrInvalidModel) {
+			t.Errorf("%s: ValidateModel = %v; want ErrInvalidModel", name, err)
+		}
+	}
+}
+
+func TestUpdateModelKeepsOldModelWhenInvalid(t *testing.T) {
+	parentDir := t.TempDir()
+	targetDir := filepath.Join(parentDir, "Rime")
+	if err := UpdateModel(testGram("old"), targetDir); err != nil {
+		t.Fatalf("UpdateModel failed: %v", err)
+	}
+
+	err := UpdateModel(testGram(strings.Repeat("new", 1000))[:2000], targetDir)
+	if !errors.Is(err, ErrInvalidModel) {
+		t.Fatalf("UpdateModel(truncated) = %v; want ErrInvalidModel", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(targetDir, DefaultModelName))
+	if err != nil || string(data) != string(testGram("old")) {
+		t.Fatalf("model replaced by invalid download: %v", err)
+	}
+	entries, _ := os.ReadDir(targetDir)
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), ".tmp-") {
+			t.Fatalf("temporary file left behind: %s", entry.Name())
+		}
+	}
+}
@@ -0,0 +1,324 @@
+package updater
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"oh-my-rime-cli/internal/system"
+
+	"gopkg.in/yaml.v3"
+)
+
+// grammarSetting 启用语法模型时写入 *.custom.yaml 的一项补丁
+type grammarSetting struct {
+	key   string
+	tag   string
+	value string
+}
+
+// grammarSettings 返回启用语法模型所需的补丁，language 为模型文件名去掉 .gram 后缀
+func grammarSettings(language string) []grammarSetting {
+	return []grammarSetting{
+		{key: "grammar/language", tag: "!!str", value: language},
+		{key: "grammar/collocation_max_length", tag: "!!int", value: "5"},
+		{key: "grammar/collocation_min_length", tag: "!!int", value: "2"},
+		{key: "translator/contextual_suggestions", tag: "!!bool", value: "true"},
+		{key: "translator/max_homophones", tag: "!!int", value: "7"},
+		{key: "translator/max_homographs", tag: "!!int", value: "5"},
+	}
+}
+
+// grammarPatchState 记录修改前的内容，用于撤销
+type grammarPatchState struct {
+	// Files 以 custom 文件名为键
+	Files map[string]*grammarPatchedFile `json:"files"`
+}
+
+type grammarPatchedFile struct {
+	// Created 文件由本工具创建，撤销后若补丁为空则删除
+	Created bool `json:"created,omitempty"`
+	// Keys 修改前的值（YAML），不存在的键记为 nil
+	Keys map[string]*string `json:"keys"`
+}
+
+func grammarStatePath(targetDir string) string {
+	return filepath.Join(stateDir(targetDir), "grammar-patch.json")
+}
+
+// ListSchemas 列出目标目录中的输入方案（*.schema.yaml），返回方案 ID
+func ListSchemas(targetDir string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(system.ExpandHomeDir(targetDir), "*.schema.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	var schemas []string
+	for _, match := range matches {
+		schemas = append(schemas, strings.TrimSuffix(filepath.Base(match), ".schema.yaml"))
+	}
+	sort.Strings(schemas)
+	return schemas, nil
+}
+
+// GrammarPatchedSchemas 返回已由本工具启用语法模型的方案
+func GrammarPatchedSchemas(targetDir string) ([]string, error) {
+	state, err := loadGrammarState(system.ExpandHomeDir(targetDir))
+	if err != nil {
+		return nil, err
+	}
+	var schemas []string
+	for name := range state.Files {
+		schemas = append(schemas, strings.TrimSuffix(name, ".custom.yaml"))
+	}
+	sort.Strings(schemas)
+	return schemas, nil
+}
+
+// EnableGrammar 在所选方案的 *.custom.yaml 中启用语法模型，保留已有的补丁和注释。
+// 首次修改前的值会被记录，可通过 DisableGrammar 撤销
+func EnableGrammar(targetDir, modelName string, schemas []string) error {
+	targetDir = system.ExpandHomeDir(targetDir)
+	if err := ValidateModelName(modelName); err != nil {
+		return err
+	}
+	language := strings.TrimSuffix(modelName, filepath.Ext(modelName))
+
+	lock, err := acquireLock(targetDir, 0)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
+	state, err := loadGrammarState(targetDir)
+	if err != nil {
+		return err
+	}
+
+	for _, schema := range schemas {
+		name := schema + ".custom.yaml"
+		path := filepath.Join(targetDir, name)
+		doc, created, err := readCustomYAML(path)
+		if err != nil {
+			return fmt.Errorf("读取 %s 失败: %v", name, err)
+		}
+		patch := patchMapping(doc)
+
+		// 只在第一次修改时记录原值，重复启用不会覆盖
+		record := state.Files[name]
+		if record == nil {
+			record = &grammarPatchedFile{Created: created, Keys: make(map[string]*string)}
+			state.Files[name] = record
+		}
+		for _, setting := range grammarSettings(language) {
+			if _, ok := record.Keys[setting.key]; !ok {
+				record.Keys[setting.key] = encodeNode(mappingValue(patch, setting.key))
+			}
+			setMappingValue(patch, setting.key, &yaml.Node{Kind: yaml.ScalarNode, Tag: setting.tag, Value: setting.value})
+		}
+
+		if err := writeCustomYAML(path, doc); err != nil {
+			return fmt.Errorf("写入 %s 失败: %v", name, err)
+		}
+		fmt.Printf("✅ 已为方案 %s 启用语法模型 %s\n", schema, language)
+	}
+	return saveGrammarState(targetDir, state)
+}
+
+// DisableGrammar 撤销 EnableGrammar 的修改，恢复各键原来的值
+func DisableGrammar(targetDir string) error {
+	targetDir = system.ExpandHomeDir(targetDir)
+	lock, err := acquireLock(targetDir, 0)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
+	state, err := loadGrammarState(targetDir)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(state.Files))
+	for name := range state.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		record := state.Files[name]
+		path := filepath.Join(targetDir, name)
+		doc, missing, err := readCustomYAML(path)
+		if err != nil {
+			return fmt.Errorf("读取 %s 失败: %v", name, err)
+		}
+		if !missing {
+			patch := patchMapping(doc)
+			for key, old := range record.Keys {
+				if old == nil {
+					removeMappingValue(patch, key)
+					continue
+				}
+				value, err := decodeNode(*old)
+				if err != nil {
+					return fmt.Errorf("恢复 %s 的 %s 失败: %v", name, key, err)
+				}
+				setMappingValue(patch, key, value)
+			}
+
+			if record.Created && len(patch.Content) == 0 {
+				if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+					return err
+				}
+			} else if err := writeCustomYAML(path, doc); err != nil {
+				return fmt.Errorf("写入 %s 失败: %v", name, err)
+			}
+		}
+		delete(state.Files, name)
+		fmt.Printf("已撤销 %s 中的语法模型设置\n", name)
+	}
+	return saveGrammarState(targetDir, state)
+}
+
+func loadGrammarState(targetDir string) (*grammarPatchState, error) {
+	state := &grammarPatchState{Files: make(map[string]*grammarPatchedFile)}
+	data, err := os.ReadFile(grammarStatePath(targetDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("语法模型修改记录格式错误: %v", err)
+	}
+	if state.Files == nil {
+		state.Files = make(map[string]*grammarPatchedFile)
+	}
+	return state, nil
+}
+
+func saveGrammarState(targetDir string, state *grammarPatchState) error {
+	path := grammarStatePath(targetDir)
+	if len(state.Files) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// readCustomYAML 读取 custom 文件，文件不存在时返回空文档并将 missing 置为 true
+func readCustomYAML(path string) (doc *yaml.Node, missing bool, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, false, err
+	}
+	missing = os.IsNotExist(err)
+
+	doc = &yaml.Node{}
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := yaml.Unmarshal(data, doc); err != nil {
+			return nil, false, err
+		}
+	}
+	if doc.Kind == 0 {
+		doc.Kind = yaml.DocumentNode
+	}
+	if len(doc.Content) == 0 {
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, false, fmt.Errorf("顶层不是映射")
+	}
+	return doc, missing, nil
+}
+
+func writeCustomYAML(path string, doc *yaml.Node) error {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, buf.Bytes(), 0644, nil)
+}
+
+// patchMapping 返回文档中的 patch 映射，不存在时创建
+func patchMapping(doc *yaml.Node) *yaml.Node {
+	root := doc.Content[0]
+	patch := mappingValue(root, "patch")
+	if patch == nil || patch.Kind != yaml.MappingNode {
+		patch = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		setMappingValue(root, "patch", patch)
+	}
+	return patch
+}
+
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue 替换已有键的值（保留键上的注释），或在末尾追加
+func setMappingValue(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			old := mapping.Content[i+1]
+			value.LineComment, value.FootComment = old.LineComment, old.FootComment
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+func removeMappingValue(mapping *yaml.Node, key string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+func encodeNode(node *yaml.Node) *string {
+	if node == nil {
+		return nil
+	}
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		return nil
+	}
+	value := string(data)
+	return &value
+}
+
+func decodeNode(value string) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}, nil
+	}
+	return doc.Content[0], nil
+}
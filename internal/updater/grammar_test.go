@@ -0,0 +1,101 @@
+package updater
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testCustomYAML = `# 用户自己的设置
+patch:
+  # 每页候选数
+  menu/page_size: 8
+  translator/max_homophones: 3 # 原来的值
+`
+
+func TestEnableAndDisableGrammar(t *testing.T) {
+	targetDir := t.TempDir()
+	for _, name := range []string{"rime_mint.schema.yaml", "double_pinyin.schema.yaml", "default.yaml"} {
+		if err := os.WriteFile(filepath.Join(targetDir, name), []byte("schema: {}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	customPath := filepath.Join(targetDir, "rime_mint.custom.yaml")
+	if err := os.WriteFile(customPath, []byte(testCustomYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	schemas, err := ListSchemas(targetDir)
+	if err != nil || !reflect.DeepEqual(schemas, []string{"double_pinyin", "rime_mint"}) {
+		t.Fatalf("ListSchemas = %v, %v", schemas, err)
+	}
+
+	if err := EnableGrammar(targetDir, "wanxiang-lts-zh-hant.gram", schemas); err != nil {
+		t.Fatalf("EnableGrammar failed: %v", err)
+	}
+	// 重复启用不应覆盖记录的原值
+	if err := EnableGrammar(targetDir, "wanxiang-lts-zh-hans.gram", []string{"rime_mint"}); err != nil {
+		t.Fatalf("EnableGrammar again failed: %v", err)
+	}
+
+	data, err := os.ReadFile(customPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	patched := string(data)
+	for _, want := range []string{
+		"# 用户自己的设置",
+		"# 每页候选数",
+		"menu/page_size: 8",
+		"translator/max_homophones: 7 # 原来的值",
+		"grammar/language: wanxiang-lts-zh-hans",
+		"translator/contextual_suggestions: true",
+	} {
+		if !strings.Contains(patched, want) {
+			t.Errorf("patched custom file missing %q:\n%s", want, patched)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "double_pinyin.custom.yaml")); err != nil {
+		t.Fatalf("custom file not created: %v", err)
+	}
+
+	patchedSchemas, err := GrammarPatchedSchemas(targetDir)
+	if err != nil || !reflect.DeepEqual(patchedSchemas, []string{"double_pinyin", "rime_mint"}) {
+		t.Fatalf("GrammarPatchedSchemas = %v, %v", patchedSchemas, err)
+	}
+
+	if err := DisableGrammar(targetDir); err != nil {
+		t.Fatalf("DisableGrammar failed: %v", err)
+	}
+	data, err = os.ReadFile(customPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := string(data)
+	if strings.Contains(restored, "grammar/") || strings.Contains(restored, "contextual_suggestions") {
+		t.Errorf("grammar settings left after revert:\n%s", restored)
+	}
+	for _, want := range []string{"# 每页候选数", "translator/max_homophones: 3 # 原来的值"} {
+		if !strings.Contains(restored, want) {
+			t.Errorf("restored custom file missing %q:\n%s", want, restored)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "double_pinyin.custom.yaml")); !os.IsNotExist(err) {
+		t.Fatalf("created custom file not removed: %v", err)
+	}
+	if _, err := os.Stat(grammarStatePath(targetDir)); !os.IsNotExist(err) {
+		t.Fatalf("grammar state not removed: %v", err)
+	}
+}
+
+func TestEnableGrammarRejectsNonMappingFile(t *testing.T) {
+	targetDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(targetDir, "rime_mint.custom.yaml"), []byte("- a\n- b\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := EnableGrammar(targetDir, DefaultModelName, []string{"rime_mint"}); err == nil {
+		t.Fatal("EnableGrammar accepted a custom file that is not a mapping")
+	}
+}
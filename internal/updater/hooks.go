@@ -0,0 +1,207 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"oh-my-rime-cli/internal/downloader"
+	"oh-my-rime-cli/internal/system"
+)
+
+// HookStage 钩子执行的阶段
+type HookStage string
+
+const (
+	// HookPreDownload 下载前执行，失败时取消更新
+	HookPreDownload HookStage = "pre-download"
+	// HookPreExtract 备份完成、写入目标目录前执行，失败时取消更新
+	HookPreExtract HookStage = "pre-extract"
+	// HookPostExtract 写入完成后执行，失败时可回滚
+	HookPostExtract HookStage = "post-extract"
+	// HookPostFailure 更新失败（已回滚）后执行
+	HookPostFailure HookStage = "post-failure"
+)
+
+// HookStages 全部钩子阶段
+var HookStages = []HookStage{HookPreDownload, HookPreExtract, HookPostExtract, HookPostFailure}
+
+// ErrHookFailed 钩子以非零状态退出
+var ErrHookFailed = errors.New("钩子执行失败")
+
+// 单个钩子的最长执行时间
+const hookTimeout = 10 * time.Minute
+
+// Hooks 更新前后执行的用户命令或脚本
+type Hooks struct {
+	// Dir 钩子脚本目录，文件名（不含扩展名）与阶段同名，如 pre-download.sh
+	Dir string
+	// Commands 各阶段通过 shell 执行的命令，在 Dir 中的脚本之后执行
+	Commands map[HookStage][]string
+	// RollbackOnPostFailure post-extract 钩子失败时恢复备份并视为更新失败
+	RollbackOnPostFailure bool
+}
+
+// HookEnv 传给钩子的环境变量
+type HookEnv struct {
+	Operation string
+	TargetDir string
+	BackupDir string
+	Source    string
+	Version   string
+	// Error 仅 post-failure 阶段设置
+	Error string
+}
+
+// HooksDir 默认的钩子脚本目录
+func HooksDir() string {
+	return filepath.Join(system.AppConfigDir(), "hooks")
+}
+
+// DefaultHooks 使用默认钩子目录中的脚本
+func DefaultHooks() *Hooks {
+	return &Hooks{Dir: HooksDir()}
+}
+
+// assetVersion 从下载地址推断资源版本，如 .../releases/download/v1.2.3/oh-my-rime.zip，无法推断时返回空字符串
+func assetVersion(source string) string {
+	return downloader.ReleaseTag(source)
+}
+
+// Run 执行某一阶段的全部钩子，任一钩子失败即返回错误。h 为 nil 时不执行任何操作
+func (h *Hooks) Run(stage HookStage, env HookEnv) error {
+	if h == nil {
+		return nil
+	}
+	commands, err := h.commands(stage)
+	if err != nil {
+		return err
+	}
+
+	for _, command := range commands {
+		fmt.Printf("执行 %s 钩子: %s\n", stage, strings.Join(command, " "))
+		if err := runHook(command, stage, env); err != nil {
+			return fmt.Errorf("%w: %s %s: %v", ErrHookFailed, stage, strings.Join(command, " "), err)
+		}
+	}
+	return nil
+}
+
+// commands 返回阶段对应的命令行：先是钩子目录中的脚本（按文件名排序），再是配置的命令
+func (h *Hooks) commands(stage HookStage) ([][]string, error) {
+	var commands [][]string
+	if h.Dir != "" {
+		entries, err := os.ReadDir(h.Dir)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("读取钩子目录失败: %v", err)
+		}
+		var scripts []string
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || strings.TrimSuffix(name, filepath.Ext(name)) != string(stage) {
+				continue
+			}
+			scripts = append(scripts, filepath.Join(h.Dir, name))
+		}
+		sort.Strings(scripts)
+		for _, script := range scripts {
+			commands = append(commands, scriptCommand(script))
+		}
+	}
+	for _, command := range h.Commands[stage] {
+		if strings.TrimSpace(command) != "" {
+			commands = append(commands, shellCommand(command))
+		}
+	}
+	return commands, nil
+}
+
+func scriptCommand(script string) []string {
+	if runtime.GOOS == "windows" && strings.EqualFold(filepath.Ext(script), ".ps1") {
+		return []string{"powershell", "-NoProfile", "-ExecutionPolicy", "Bypass", "-File", script}
+	}
+	return []string{script}
+}
+
+func shellCommand(command string) []string {
+	if runtime.GOOS == "windows" {
+		return []string{"cmd", "/C", command}
+	}
+	return []string{"sh", "-c", command}
+}
+
+func runHook(command []string, stage HookStage, env HookEnv) error {
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if info, err := os.Stat(env.TargetDir); err == nil && info.IsDir() {
+		cmd.Dir = env.TargetDir
+	}
+	cmd.Env = append(os.Environ(),
+		"OH_MY_RIME_HOOK="+string(stage),
+		"OH_MY_RIME_OPERATION="+env.Operation,
+		"OH_MY_RIME_TARGET_DIR="+env.TargetDir,
+		"OH_MY_RIME_BACKUP_DIR="+env.BackupDir,
+		"OH_MY_RIME_SOURCE="+env.Source,
+		"OH_MY_RIME_VERSION="+env.Version,
+		"OH_MY_RIME_ERROR="+env.Error,
+	)
+	return cmd.Run()
+}
+
+func (o Options) version() string {
+	if o.Version != "" {
+		return o.Version
+	}
+	return assetVersion(o.Source)
+}
+
+// runFailureHooks 执行 post-failure 钩子，钩子本身的错误只输出不返回
+func (o Options) runFailureHooks(env HookEnv, cause error) {
+	env.Error = cause.Error()
+	if err := o.Hooks.Run(HookPostFailure, env); err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+	}
+}
+
+// failBeforeUpdate 在修改目标目录前失败（更新包未通过检查、目录被占用等）时执行 post-failure 钩子，返回原错误
+func (o Options) failBeforeUpdate(operation, targetDir string, err error) error {
+	o.runFailureHooks(HookEnv{
+		Operation: operation,
+		TargetDir: targetDir,
+		Source:    o.Source,
+		Version:   o.version(),
+	}, err)
+	return err
+}
+
+// RunPreDownloadHooks 在下载前执行 pre-download 钩子，失败时应取消更新
+func RunPreDownloadHooks(hooks *Hooks, operation, targetDir, source string) error {
+	return hooks.Run(HookPreDownload, HookEnv{
+		Operation: operation,
+		TargetDir: system.ExpandHomeDir(targetDir),
+		Source:    source,
+		Version:   assetVersion(source),
+	})
+}
+
+// RunFailureHooks 在更新开始前失败（如下载失败）时执行 post-failure 钩子
+func RunFailureHooks(hooks *Hooks, operation, targetDir, source string, cause error) {
+	env := HookEnv{
+		Operation: operation,
+		TargetDir: system.ExpandHomeDir(targetDir),
+		Source:    source,
+		Version:   assetVersion(source),
+	}
+	Options{Hooks: hooks}.runFailureHooks(env, cause)
+}
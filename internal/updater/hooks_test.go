@@ -0,0 +1,173 @@
+package updater
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func writeHook(t *testing.T, dir string, stage HookStage, script string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, string(stage)+".sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func skipWithoutShell(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hook tests use shell scripts")
+	}
+}
+
+func readModel(t *testing.T, targetDir string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(targetDir, DefaultModelName))
+	if err != nil {
+		t.Fatalf("read model: %v", err)
+	}
+	return string(data)
+}
+
+func TestHooksReceiveEnvironment(t *testing.T) {
+	skipWithoutShell(t)
+	parentDir := t.TempDir()
+	targetDir := filepath.Join(parentDir, "Rime")
+	hooksDir := filepath.Join(parentDir, "hooks")
+	envFile := filepath.Join(parentDir, "env.txt")
+	writeHook(t, hooksDir, HookPostExtract, `echo "$OH_MY_RIME_HOOK|$OH_MY_RIME_OPERATION|$OH_MY_RIME_TARGET_DIR|$OH_MY_RIME_BACKUP_DIR|$OH_MY_RIME_VERSION" > '`+envFile+`'`)
+
+	if err := UpdateModel(testGram("old"), targetDir); err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{
+		Source: "https://example.com/releases/download/v1.2.3/wanxiang-lts-zh-hans.gram",
+		Hooks:  &Hooks{Dir: hooksDir},
+	}
+	if err := UpdateModelWithOptions(testGram("new"), targetDir, opts); err != nil {
+		t.Fatalf("UpdateModelWithOptions failed: %v", err)
+	}
+
+	data, err := os.ReadFile(envFile)
+	if err != nil {
+		t.Fatalf("post-extract hook did not run: %v", err)
+	}
+	fields := strings.Split(strings.TrimSpace(string(data)), "|")
+	if len(fields) != 5 || fields[0] != "post-extract" || fields[1] != OperationModel || fields[2] != targetDir || fields[4] != "v1.2.3" {
+		t.Fatalf("hook env = %q", data)
+	}
+	if !strings.HasPrefix(fields[3], filepath.Join(parentDir, "Rime.backups")) {
+		t.Fatalf("backup dir = %q", fields[3])
+	}
+}
+
+func TestPreExtractHookAbortsUpdate(t *testing.T) {
+	skipWithoutShell(t)
+	parentDir := t.TempDir()
+	targetDir := filepath.Join(parentDir, "Rime")
+	hooksDir := filepath.Join(parentDir, "hooks")
+	failureFile := filepath.Join(parentDir, "failure.txt")
+	writeHook(t, hooksDir, HookPreExtract, "exit 3")
+	writeHook(t, hooksDir, HookPostFailure, `echo "$OH_MY_RIME_ERROR" > '`+failureFile+`'`)
+
+	if err := UpdateModel(testGram("old"), targetDir); err != nil {
+		t.Fatal(err)
+	}
+	err := UpdateModelWithOptions(testGram("new"), targetDir, Options{Hooks: &Hooks{Dir: hooksDir}})
+	if !errors.Is(err, ErrHookFailed) {
+		t.Fatalf("UpdateModelWithOptions = %v; want ErrHookFailed", err)
+	}
+	if readModel(t, targetDir) != string(testGram("old")) {
+		t.Fatal("model replaced although pre-extract hook failed")
+	}
+	if data, err := os.ReadFile(failureFile); err != nil || !strings.Contains(string(data), "pre-extract") {
+		t.Fatalf("post-failure hook output = %q, %v", data, err)
+	}
+}
+
+func TestPostExtractHookFailure(t *testing.T) {
+	skipWithoutShell(t)
+
+	for _, rollback := range []bool{false, true} {
+		parentDir := t.TempDir()
+		targetDir := filepath.Join(parentDir, "Rime")
+		if err := UpdateModel(testGram("old"), targetDir); err != nil {
+			t.Fatal(err)
+		}
+
+		hooks := &Hooks{
+			Commands:              map[HookStage][]string{HookPostExtract: {"exit 1"}},
+			RollbackOnPostFailure: rollback,
+		}
+		err := UpdateModelWithOptions(testGram("new"), targetDir, Options{Hooks: hooks})
+
+		want := string(testGram("new"))
+		if rollback {
+			want = string(testGram("old"))
+			if !errors.Is(err, ErrHookFailed) {
+				t.Fatalf("rollback: UpdateModelWithOptions = %v; want ErrHookFailed", err)
+			}
+		} else if err != nil {
+			t.Fatalf("UpdateModelWithOptions = %v; want nil", err)
+		}
+		if readModel(t, targetDir) != want {
+			t.Errorf("rollback=%v: unexpected model content", rollback)
+		}
+	}
+}
+
+func TestRunPreDownloadHooks(t *testing.T) {
+	skipWithoutShell(t)
+	hooks := &Hooks{Commands: map[HookStage][]string{HookPreDownload: {`test "$OH_MY_RIME_OPERATION" = dict`}}}
+
+	if err := RunPreDownloadHooks(hooks, OperationDict, "", ""); err != nil {
+		t.Fatalf("RunPreDownloadHooks(dict) = %v", err)
+	}
+	if err := RunPreDownloadHooks(hooks, OperationModel, "", ""); !errors.Is(err, ErrHookFailed) {
+		t.Fatalf("RunPreDownloadHooks(model) = %v; want ErrHookFailed", err)
+	}
+	if err := RunPreDownloadHooks(nil, OperationModel, "", ""); err != nil {
+		t.Fatalf("RunPreDownloadHooks(nil) = %v", err)
+	}
+}
+
+func TestPostFailureHookRunsForInvalidPackage(t *testing.T) {
+	skipWithoutShell(t)
+	parentDir := t.TempDir()
+	targetDir := filepath.Join(parentDir, "Rime")
+	hooksDir := filepath.Join(parentDir, "hooks")
+	failureFile := filepath.Join(parentDir, "failure.txt")
+	writeHook(t, hooksDir, HookPostFailure, `echo "$OH_MY_RIME_OPERATION|$OH_MY_RIME_ERROR" >> '`+failureFile+`'`)
+	opts := Options{Hooks: &Hooks{Dir: hooksDir}}
+
+	if err := UpdateMainSchemeWithOptions([]byte("not a zip"), targetDir, opts); !IsInvalidPackage(err) {
+		t.Fatalf("UpdateMainSchemeWithOptions = %v; want invalid package", err)
+	}
+	// 超出解压限制的词库在修改目标目录前被拒绝
+	dicts := testZip(t, zipEntry{name: "dicts/a.dict.yaml", body: "a"}, zipEntry{name: "dicts/b.dict.yaml", body: "b"})
+	limited := opts
+	limited.Limits = &ExtractLimits{MaxEntries: 1}
+	if err := UpdateDictWithOptions(dicts, targetDir, limited); !IsInvalidPackage(err) {
+		t.Fatalf("UpdateDictWithOptions = %v; want invalid package", err)
+	}
+	if err := UpdateModelWithOptions([]byte("not a model"), targetDir, opts); !IsInvalidPackage(err) {
+		t.Fatalf("UpdateModelWithOptions = %v; want invalid package", err)
+	}
+
+	data, err := os.ReadFile(failureFile)
+	if err != nil {
+		t.Fatalf("post-failure hook did not run: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], OperationMainScheme+"|") ||
+		!strings.HasPrefix(lines[1], OperationDict+"|") || !strings.HasPrefix(lines[2], OperationModel+"|") {
+		t.Fatalf("post-failure hook output = %q", data)
+	}
+}
@@ -0,0 +1,247 @@
+package updater
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"oh-my-rime-cli/internal/system"
+)
+
+// 更新操作类型，与 GUI 的 actionType 保持一致
+const (
+	OperationMainScheme = "main"
+	OperationModel      = "model"
+	OperationDict       = "dict"
+)
+
+var operationNames = map[string]string{
+	OperationMainScheme: "主方案更新",
+	OperationModel:      "模型更新",
+	OperationDict:       "词库更新",
+}
+
+// PendingUpdate 记录一次未正常结束的更新（更新日志）
+type PendingUpdate struct {
+	Operation string    `json:"operation"`
+	Source    string    `json:"source,omitempty"`
+	TargetDir string    `json:"targetDir"`
+	BackupDir string    `json:"backupDir,omitempty"`
+	StartedAt time.Time `json:"startedAt"`
+	PID       int       `json:"pid"`
+	// ModelName 模型更新写入的文件名
+	ModelName string `json:"modelName,omitempty"`
+	// Written 中断前已写入的文件（绝对路径）
+	Written []string `json:"-"`
+
+	path string
+}
+
+// OperationName 返回操作的中文名称
+func (p *PendingUpdate) OperationName() string {
+	if name, ok := operationNames[p.Operation]; ok {
+		return name
+	}
+	return p.Operation
+}
+
+// journal 更新日志文件：首行为 JSON 头，其后每行一个已写入的文件路径
+type journal struct {
+	path string
+	file *os.File
+}
+
+func journalDir() string {
+	return filepath.Join(system.AppConfigDir(), "journal")
+}
+
+func journalPath(targetDir string) string {
+	sum := sha1.Sum([]byte(filepath.Clean(targetDir)))
+	return filepath.Join(journalDir(), hex.EncodeToString(sum[:8])+".journal")
+}
+
+// beginJournal 在修改目标目录之前写入更新日志
+func beginJournal(header PendingUpdate) (*journal, error) {
+	if err := os.MkdirAll(journalDir(), 0755); err != nil {
+		return nil, err
+	}
+
+	path := journalPath(header.TargetDir)
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(header)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	lines := append(data, '\n')
+	for _, written := range header.Written {
+		lines = append(lines, written+"\n"...)
+	}
+	if _, err := file.Write(lines); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &journal{path: path, file: file}, nil
+}
+
+// record 追加一个已写入的文件
+func (j *journal) record(path string) {
+	if j == nil {
+		return
+	}
+	if _, err := j.file.WriteString(path + "\n"); err != nil {
+		fmt.Printf("写入更新日志失败: %v\n", err)
+	}
+}
+
+// finish 更新结束（成功或已回滚）后删除更新日志
+func (j *journal) finish() {
+	if j == nil {
+		return
+	}
+	j.file.Close()
+	if err := os.Remove(j.path); err != nil && !os.IsNotExist(err) {
+		fmt.Printf("删除更新日志失败: %v\n", err)
+	}
+}
+
+// close 保留更新日志，仅关闭文件（用于回滚失败的情况）
+func (j *journal) close() {
+	if j == nil {
+		return
+	}
+	j.file.Close()
+}
+
+// PendingUpdates 返回所有遗留的更新日志
+func PendingUpdates() ([]*PendingUpdate, error) {
+	entries, err := os.ReadDir(journalDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var pending []*PendingUpdate
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".journal") {
+			continue
+		}
+		path := filepath.Join(journalDir(), entry.Name())
+		update, err := readJournal(path)
+		if err != nil {
+			fmt.Printf("读取更新日志失败 %s: %v\n", path, err)
+			continue
+		}
+		if lockHeld(update.TargetDir) {
+			// 更新仍在其他进程中进行，并非中断
+			continue
+		}
+		pending = append(pending, update)
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].StartedAt.Before(pending[j].StartedAt)
+	})
+	return pending, nil
+}
+
+func readJournal(path string) (*PendingUpdate, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("更新日志为空")
+	}
+
+	var update PendingUpdate
+	if err := json.Unmarshal(scanner.Bytes(), &update); err != nil {
+		return nil, fmt.Errorf("更新日志格式错误: %v", err)
+	}
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			update.Written = append(update.Written, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	update.path = path
+	return &update, nil
+}
+
+// RollbackPendingUpdate 将中断的更新回滚到记录的备份；
+// 若更新前目标目录不存在（无备份），则删除已写入的文件
+func RollbackPendingUpdate(update *PendingUpdate) error {
+	lock, err := acquireLock(update.TargetDir, 0)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
+	if update.BackupDir != "" {
+		if _, err := os.Stat(update.BackupDir); err != nil {
+			return fmt.Errorf("备份不可用: %v", err)
+		}
+		if err := restoreBackup(update.TargetDir, update.BackupDir); err != nil {
+			return fmt.Errorf("备份恢复失败: %v", err)
+		}
+		fmt.Printf("已从备份恢复: %s\n", update.BackupDir)
+	} else {
+		for _, written := range update.Written {
+			if err := os.Remove(written); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("删除已写入文件失败: %v", err)
+			}
+		}
+		fmt.Printf("已删除中断更新写入的 %d 个文件\n", len(update.Written))
+	}
+	return DiscardPendingUpdate(update)
+}
+
+// DiscardPendingUpdate 删除更新日志，保留目标目录当前状态
+func DiscardPendingUpdate(update *PendingUpdate) error {
+	if err := os.Remove(update.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// ResumePendingUpdate 使用重新下载的数据继续中断的更新，
+// 沿用原有备份，避免把中断后的半成品状态当作备份。
+// opts 为调用方按当前配置创建的更新选项（钩子、受保护文件、解压限制等），Source 与 ModelName 以更新日志为准
+func ResumePendingUpdate(update *PendingUpdate, data []byte, opts Options) error {
+	opts.Source, opts.ModelName, opts.resume = update.Source, update.ModelName, update
+	switch update.Operation {
+	case OperationMainScheme:
+		return UpdateMainSchemeWithOptions(data, update.TargetDir, opts)
+	case OperationModel:
+		return UpdateModelWithOptions(data, update.TargetDir, opts)
+	case OperationDict:
+		return UpdateDictWithOptions(data, update.TargetDir, opts)
+	}
+	return fmt.Errorf("未知的更新类型: %s", update.Operation)
+}
@@ -0,0 +1,159 @@
+package updater
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestUpdateRemovesJournalOnCompletion(t *testing.T) {
+	targetDir := filepath.Join(t.TempDir(), "Rime")
+
+	if err := UpdateMainScheme(testZip(t, zipEntry{name: "new.yaml", body: "new"}), targetDir); err != nil {
+		t.Fatalf("UpdateMainScheme returned error: %v", err)
+	}
+
+	pending, err := PendingUpdates()
+	if err != nil {
+		t.Fatalf("PendingUpdates returned error: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("pending updates = %d; want 0", len(pending))
+	}
+}
+
+func TestRollbackPendingUpdateRestoresBackup(t *testing.T) {
+	parentDir := t.TempDir()
+	targetDir := filepath.Join(parentDir, "Rime")
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		t.Fatalf("create target dir: %v", err)
+	}
+	existingPath := filepath.Join(targetDir, "default.custom.yaml")
+	if err := os.WriteFile(existingPath, []byte("old"), 0644); err != nil {
+		t.Fatalf("write existing file: %v", err)
+	}
+
+	// 模拟更新过程中断电：已写入日志和部分文件，但日志未被删除
+	backupDir, _, err := createBackup(targetDir)
+	if err != nil {
+		t.Fatalf("create backup: %v", err)
+	}
+	j, err := beginJournal(PendingUpdate{
+		Operation: OperationMainScheme,
+		TargetDir: targetDir,
+		BackupDir: backupDir,
+		StartedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("begin journal: %v", err)
+	}
+	halfWritten := filepath.Join(targetDir, "new.yaml")
+	j.record(halfWritten)
+	if err := os.WriteFile(halfWritten, []byte("partial"), 0644); err != nil {
+		t.Fatalf("write partial file: %v", err)
+	}
+	if err := os.WriteFile(existingPath, []byte("partial"), 0644); err != nil {
+		t.Fatalf("overwrite existing file: %v", err)
+	}
+	j.close()
+
+	pending, err := PendingUpdates()
+	if err != nil {
+		t.Fatalf("PendingUpdates returned error: %v", err)
+	}
+	if len(pending) != 1 {
+		t.Fatalf("pending updates = %d; want 1", len(pending))
+	}
+	if got := pending[0].Written; len(got) != 1 || got[0] != halfWritten {
+		t.Fatalf("written files = %v; want [%s]", got, halfWritten)
+	}
+
+	if err := RollbackPendingUpdate(pending[0]); err != nil {
+		t.Fatalf("RollbackPendingUpdate returned error: %v", err)
+	}
+	if data, err := os.ReadFile(existingPath); err != nil || string(data) != "old" {
+		t.Fatalf("existing file after rollback = %q, %v; want old", data, err)
+	}
+	if _, err := os.Stat(halfWritten); !os.IsNotExist(err) {
+		t.Fatalf("partial file exists after rollback; stat error: %v", err)
+	}
+	if pending, _ := PendingUpdates(); len(pending) != 0 {
+		t.Fatalf("pending updates after rollback = %d; want 0", len(pending))
+	}
+}
+
+func TestRollbackPendingUpdateWithoutBackupRemovesWrittenFiles(t *testing.T) {
+	targetDir := filepath.Join(t.TempDir(), "Rime")
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		t.Fatalf("create target dir: %v", err)
+	}
+
+	j, err := beginJournal(PendingUpdate{
+		Operation: OperationModel,
+		TargetDir: targetDir,
+		StartedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("begin journal: %v", err)
+	}
+	modelPath := filepath.Join(targetDir, "wanxiang-lts-zh-hans.gram")
+	j.record(modelPath)
+	if err := os.WriteFile(modelPath, []byte("partial"), 0644); err != nil {
+		t.Fatalf("write partial model: %v", err)
+	}
+	j.close()
+
+	pending, err := PendingUpdates()
+	if err != nil || len(pending) != 1 {
+		t.Fatalf("PendingUpdates = %d, %v; want 1", len(pending), err)
+	}
+	if err := RollbackPendingUpdate(pending[0]); err != nil {
+		t.Fatalf("RollbackPendingUpdate returned error: %v", err)
+	}
+	if _, err := os.Stat(modelPath); !os.IsNotExist(err) {
+		t.Fatalf("partial model exists after rollback; stat error: %v", err)
+	}
+}
+
+func TestResumePendingUpdateKeepsProtectedFiles(t *testing.T) {
+	targetDir := filepath.Join(t.TempDir(), "Rime")
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		t.Fatalf("create target dir: %v", err)
+	}
+	customPath := filepath.Join(targetDir, "default.custom.yaml")
+	if err := os.WriteFile(customPath, []byte("mine"), 0644); err != nil {
+		t.Fatalf("write custom file: %v", err)
+	}
+
+	j, err := beginJournal(PendingUpdate{
+		Operation: OperationMainScheme,
+		TargetDir: targetDir,
+		StartedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("begin journal: %v", err)
+	}
+	j.close()
+
+	pending, err := PendingUpdates()
+	if err != nil || len(pending) != 1 {
+		t.Fatalf("PendingUpdates = %d, %v; want 1", len(pending), err)
+	}
+	data := testZip(t,
+		zipEntry{name: "default.custom.yaml", body: "upstream"},
+		zipEntry{name: "default.yaml", body: "new"},
+	)
+	if err := ResumePendingUpdate(pending[0], data, Options{Protected: []string{"*.custom.yaml"}}); err != nil {
+		t.Fatalf("ResumePendingUpdate returned error: %v", err)
+	}
+	if got, err := os.ReadFile(customPath); err != nil || string(got) != "mine" {
+		t.Fatalf("protected file after resume = %q, %v; want mine", got, err)
+	}
+	if got, err := os.ReadFile(filepath.Join(targetDir, "default.yaml")); err != nil || string(got) != "new" {
+		t.Fatalf("default.yaml after resume = %q, %v; want new", got, err)
+	}
+	if pending, _ := PendingUpdates(); len(pending) != 0 {
+		t.Fatalf("pending updates after resume = %d; want 0", len(pending))
+	}
+}
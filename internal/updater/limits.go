@@ -0,0 +1,166 @@
+package updater
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"oh-my-rime-cli/internal/downloader"
+	"oh-my-rime-cli/internal/system"
+)
+
+// ErrLimitExceeded zip 超出解压限制
+var ErrLimitExceeded = errors.New("超出解压限制")
+
+// ExtractLimits 解压资源限制，字段为 0 表示不限制
+type ExtractLimits struct {
+	// MaxTotalSize 解压后的总大小
+	MaxTotalSize int64
+	// MaxFileSize 单个文件解压后的大小
+	MaxFileSize int64
+	// MaxEntries zip 条目数
+	MaxEntries int
+	// MaxCompressionRatio 单个文件的最大压缩比（解压后大小 / 压缩后大小）
+	MaxCompressionRatio float64
+}
+
+// DefaultExtractLimits 适用于 Rime 方案包的默认限制：
+// 薄荷方案完整包解压后约数百 MB，词库文本压缩比通常在 10 倍以内
+var DefaultExtractLimits = ExtractLimits{
+	MaxTotalSize:        2 << 30,
+	MaxFileSize:         1 << 30,
+	MaxEntries:          20000,
+	MaxCompressionRatio: 200,
+}
+
+// 压缩比只对超过该大小的文件检查，避免小文件（如空白填充的 yaml）误判
+const ratioCheckMinSize = 1 << 20
+
+func (o Options) extractLimits() ExtractLimits {
+	if o.Limits != nil {
+		return *o.Limits
+	}
+	return DefaultExtractLimits
+}
+
+// checkArchive 按 zip 中声明的大小检查限制，返回声明的解压后总大小
+func checkArchive(files []*zip.File, limits ExtractLimits) (int64, error) {
+	if limits.MaxEntries > 0 && len(files) > limits.MaxEntries {
+		return 0, fmt.Errorf("%w: 条目数 %d 超过上限 %d", ErrLimitExceeded, len(files), limits.MaxEntries)
+	}
+
+	var total int64
+	for _, file := range files {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		size := int64(file.UncompressedSize64)
+		if size < 0 {
+			return 0, fmt.Errorf("%w: %s 声明的大小无效", ErrLimitExceeded, file.Name)
+		}
+		if limits.MaxFileSize > 0 && size > limits.MaxFileSize {
+			return 0, fmt.Errorf("%w: %s 解压后 %s，超过单文件上限 %s", ErrLimitExceeded,
+				file.Name, downloader.FormatBytes(size), downloader.FormatBytes(limits.MaxFileSize))
+		}
+		if limits.MaxCompressionRatio > 0 && size > ratioCheckMinSize {
+			compressed := int64(file.CompressedSize64)
+			if compressed <= 0 || float64(size)/float64(compressed) > limits.MaxCompressionRatio {
+				return 0, fmt.Errorf("%w: %s 压缩比异常", ErrLimitExceeded, file.Name)
+			}
+		}
+		total += size
+		if limits.MaxTotalSize > 0 && total > limits.MaxTotalSize {
+			return 0, fmt.Errorf("%w: 解压后总大小超过上限 %s", ErrLimitExceeded, downloader.FormatBytes(limits.MaxTotalSize))
+		}
+	}
+	return total, nil
+}
+
+// checkFreeSpace 检查目标目录所在磁盘是否有足够空间容纳解压内容和备份
+func checkFreeSpace(targetDir string, extractSize int64) error {
+	required := extractSize
+	if size, err := dirSize(targetDir); err == nil {
+		required += size
+	}
+
+	free, err := system.FreeDiskSpace(existingAncestor(targetDir))
+	if err != nil {
+		fmt.Printf("无法获取磁盘剩余空间，跳过检查: %v\n", err)
+		return nil
+	}
+	if uint64(required) > free {
+		return fmt.Errorf("磁盘空间不足: 需要 %s，剩余 %s",
+			downloader.FormatBytes(required), downloader.FormatBytes(int64(free)))
+	}
+	return nil
+}
+
+func existingAncestor(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	return size, err
+}
+
+// extractBudget 解压过程中按实际写入字节数执行限制，防止 zip 声明的大小被伪造
+type extractBudget struct {
+	limits  ExtractLimits
+	written int64
+}
+
+// limitReader 包装单个文件的读取器，超出限制时返回 ErrLimitExceeded
+func (b *extractBudget) limitReader(file *zip.File, r io.Reader) io.Reader {
+	return &budgetReader{budget: b, name: file.Name, r: r}
+}
+
+type budgetReader struct {
+	budget  *extractBudget
+	name    string
+	r       io.Reader
+	written int64
+}
+
+func (br *budgetReader) Read(p []byte) (int, error) {
+	n, err := br.r.Read(p)
+	br.written += int64(n)
+	br.budget.written += int64(n)
+
+	limits := br.budget.limits
+	if limits.MaxFileSize > 0 && br.written > limits.MaxFileSize {
+		return n, fmt.Errorf("%w: %s 实际解压大小超过单文件上限", ErrLimitExceeded, br.name)
+	}
+	if limits.MaxTotalSize > 0 && br.budget.written > limits.MaxTotalSize {
+		return n, fmt.Errorf("%w: 实际解压总大小超过上限", ErrLimitExceeded)
+	}
+	return n, err
+}
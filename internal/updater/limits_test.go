@@ -0,0 +1,66 @@
+package updater
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUpdateMainSchemeRejectsTooManyEntries(t *testing.T) {
+	parentDir := t.TempDir()
+	targetDir := filepath.Join(parentDir, "Rime")
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		t.Fatalf("create target dir: %v", err)
+	}
+
+	err := UpdateMainSchemeWithOptions(testZip(t,
+		zipEntry{name: "a.yaml", body: "a"},
+		zipEntry{name: "b.yaml", body: "b"},
+	), targetDir, Options{Limits: &ExtractLimits{MaxEntries: 1}})
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("UpdateMainSchemeWithOptions error = %v; want ErrLimitExceeded", err)
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "a.yaml")); !os.IsNotExist(err) {
+		t.Fatalf("file extracted despite limit; stat error: %v", err)
+	}
+	if _, err := os.Stat(backupRootDir(targetDir)); !os.IsNotExist(err) {
+		t.Fatalf("backup created despite limit; stat error: %v", err)
+	}
+}
+
+func TestUpdateDictRejectsHighCompressionRatio(t *testing.T) {
+	targetDir := filepath.Join(t.TempDir(), "Rime")
+
+	// 4 MiB 的重复内容压缩后只有几 KB，压缩比远超默认上限
+	err := UpdateDict(testZip(t,
+		zipEntry{name: "dicts/bomb.dict.yaml", body: strings.Repeat("0", 4<<20)},
+	), targetDir)
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("UpdateDict error = %v; want ErrLimitExceeded", err)
+	}
+}
+
+func TestExtractFileEnforcesActualSize(t *testing.T) {
+	targetDir := t.TempDir()
+	data := testZip(t,
+		zipEntry{name: "a.yaml", body: strings.Repeat("a", 600)},
+		zipEntry{name: "b.yaml", body: strings.Repeat("b", 600)},
+	)
+	zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("read zip: %v", err)
+	}
+
+	budget := &extractBudget{limits: ExtractLimits{MaxTotalSize: 1000}}
+	if err := extractFile(zipReader.File[0], targetDir, filepath.Join(targetDir, "a.yaml"), defaultFileMode, budget); err != nil {
+		t.Fatalf("extract first file: %v", err)
+	}
+	err = extractFile(zipReader.File[1], targetDir, filepath.Join(targetDir, "b.yaml"), defaultFileMode, budget)
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("extract second file error = %v; want ErrLimitExceeded", err)
+	}
+}
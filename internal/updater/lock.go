@@ -0,0 +1,174 @@
+package updater
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"oh-my-rime-cli/internal/system"
+)
+
+const (
+	// 等待锁时的轮询间隔
+	lockPollInterval = 500 * time.Millisecond
+	// 其他主机创建的锁无法检查进程，超过该时长视为失效
+	staleLockAge = 2 * time.Hour
+	// 内容无法解析的锁（可能正在写入）超过该时长视为失效
+	brokenLockAge = 10 * time.Second
+)
+
+// LockError 目标目录正被其他进程更新
+type LockError struct {
+	TargetDir string
+	PID       int
+	Hostname  string
+	StartedAt time.Time
+}
+
+func (e *LockError) Error() string {
+	return fmt.Sprintf("另一个更新正在运行 (pid %d，主机 %s，开始于 %s)，目标目录: %s",
+		e.PID, e.Hostname, e.StartedAt.Format("2006-01-02 15:04:05"), e.TargetDir)
+}
+
+// lockInfo 锁文件内容
+type lockInfo struct {
+	PID       int       `json:"pid"`
+	Hostname  string    `json:"hostname"`
+	StartedAt time.Time `json:"startedAt"`
+}
+
+// updateLock 基于锁文件的跨进程建议锁
+type updateLock struct {
+	path    string
+	content []byte
+}
+
+func lockPath(targetDir string) string {
+	cleanTarget := filepath.Clean(targetDir)
+	return filepath.Join(filepath.Dir(cleanTarget), filepath.Base(cleanTarget)+".lock")
+}
+
+// acquireLock 获取目标目录的更新锁，wait 为 0 时不等待
+func acquireLock(targetDir string, wait time.Duration) (*updateLock, error) {
+	path := lockPath(targetDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	hostname, _ := os.Hostname()
+	content, err := json.Marshal(lockInfo{PID: os.Getpid(), Hostname: hostname, StartedAt: time.Now()})
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(wait)
+	waiting := false
+	for {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, writeErr := file.Write(content)
+			closeErr := file.Close()
+			if writeErr != nil || closeErr != nil {
+				os.Remove(path)
+				return nil, fmt.Errorf("写入锁文件失败: %v", firstError(writeErr, closeErr))
+			}
+			return &updateLock{path: path, content: content}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("创建锁文件失败: %v", err)
+		}
+
+		holder, stale := inspectLock(path, hostname)
+		if stale {
+			fmt.Printf("发现失效的锁文件，已清理: %s\n", path)
+			continue
+		}
+		if holder == nil {
+			// 锁刚被释放或正在写入，稍后重试
+			holder = &lockInfo{}
+		}
+
+		lockErr := &LockError{TargetDir: targetDir, PID: holder.PID, Hostname: holder.Hostname, StartedAt: holder.StartedAt}
+		if !time.Now().Before(deadline) {
+			return nil, lockErr
+		}
+		if !waiting {
+			fmt.Printf("%v，等待中...\n", lockErr)
+			waiting = true
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// inspectLock 读取锁文件，失效的锁会被删除并返回 stale=true
+func inspectLock(path, hostname string) (*lockInfo, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var holder lockInfo
+	if err := json.Unmarshal(data, &holder); err != nil {
+		info, statErr := os.Stat(path)
+		if statErr == nil && time.Since(info.ModTime()) > brokenLockAge {
+			return nil, removeStaleLock(path, data)
+		}
+		return nil, false
+	}
+
+	stale := false
+	if holder.Hostname == hostname {
+		stale = !system.ProcessExists(holder.PID)
+	} else {
+		stale = time.Since(holder.StartedAt) > staleLockAge
+	}
+	if stale {
+		return &holder, removeStaleLock(path, data)
+	}
+	return &holder, false
+}
+
+// removeStaleLock 仅当锁文件内容未变化时删除，避免误删其他进程刚创建的锁
+func removeStaleLock(path string, staleContent []byte) bool {
+	current, err := os.ReadFile(path)
+	if err != nil {
+		return os.IsNotExist(err)
+	}
+	if !bytes.Equal(current, staleContent) {
+		return false
+	}
+	return os.Remove(path) == nil
+}
+
+// lockHeld 判断目标目录当前是否被其他进程持有更新锁
+func lockHeld(targetDir string) bool {
+	hostname, _ := os.Hostname()
+	holder, stale := inspectLock(lockPath(targetDir), hostname)
+	return holder != nil && !stale
+}
+
+// release 释放更新锁
+func (l *updateLock) release() {
+	if l == nil {
+		return
+	}
+	current, err := os.ReadFile(l.path)
+	if err != nil || !bytes.Equal(current, l.content) {
+		return
+	}
+	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		fmt.Printf("删除锁文件失败: %v\n", err)
+	}
+}
+
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
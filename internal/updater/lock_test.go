@@ -0,0 +1,75 @@
+package updater
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAcquireLockRejectsConcurrentUpdate(t *testing.T) {
+	targetDir := filepath.Join(t.TempDir(), "Rime")
+
+	lock, err := acquireLock(targetDir, 0)
+	if err != nil {
+		t.Fatalf("acquireLock returned error: %v", err)
+	}
+	defer lock.release()
+
+	err = UpdateModel(testGram("model"), targetDir)
+	var lockErr *LockError
+	if !errors.As(err, &lockErr) {
+		t.Fatalf("UpdateModel error = %v; want LockError", err)
+	}
+	if lockErr.PID != os.Getpid() {
+		t.Fatalf("lock holder pid = %d; want %d", lockErr.PID, os.Getpid())
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "wanxiang-lts-zh-hans.gram")); !os.IsNotExist(err) {
+		t.Fatalf("model written while locked; stat error: %v", err)
+	}
+}
+
+func TestAcquireLockWaitsForRelease(t *testing.T) {
+	targetDir := filepath.Join(t.TempDir(), "Rime")
+
+	lock, err := acquireLock(targetDir, 0)
+	if err != nil {
+		t.Fatalf("acquireLock returned error: %v", err)
+	}
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		lock.release()
+	}()
+
+	second, err := acquireLock(targetDir, 5*time.Second)
+	if err != nil {
+		t.Fatalf("acquireLock with wait returned error: %v", err)
+	}
+	second.release()
+
+	if _, err := os.Stat(lockPath(targetDir)); !os.IsNotExist(err) {
+		t.Fatalf("lock file exists after release; stat error: %v", err)
+	}
+}
+
+func TestAcquireLockRemovesStaleLock(t *testing.T) {
+	targetDir := filepath.Join(t.TempDir(), "Rime")
+	hostname, _ := os.Hostname()
+
+	// 一个不存在的进程留下的锁
+	stale, err := json.Marshal(lockInfo{PID: 1 << 30, Hostname: hostname, StartedAt: time.Now()})
+	if err != nil {
+		t.Fatalf("marshal lock: %v", err)
+	}
+	if err := os.WriteFile(lockPath(targetDir), stale, 0644); err != nil {
+		t.Fatalf("write stale lock: %v", err)
+	}
+
+	lock, err := acquireLock(targetDir, 0)
+	if err != nil {
+		t.Fatalf("acquireLock returned error: %v", err)
+	}
+	lock.release()
+}
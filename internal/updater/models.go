@@ -0,0 +1,68 @@
+package updater
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"oh-my-rime-cli/internal/system"
+)
+
+// DefaultModelName 未指定文件名时写入的模型文件（万象简体模型）
+const DefaultModelName = "wanxiang-lts-zh-hans.gram"
+
+// ModelInfo 目标目录中已安装的模型文件
+type ModelInfo struct {
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+func (o Options) modelName() string {
+	if o.ModelName != "" {
+		return o.ModelName
+	}
+	return DefaultModelName
+}
+
+// ValidateModelName 检查模型文件名：只能是目标目录下的 .gram 文件，不能包含路径
+func ValidateModelName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) || filepath.Base(name) != name {
+		return fmt.Errorf("模型文件名无效: %q", name)
+	}
+	if !strings.HasSuffix(strings.ToLower(name), ".gram") {
+		return fmt.Errorf("模型文件名必须以 .gram 结尾: %s", name)
+	}
+	return nil
+}
+
+// ListModels 列出目标目录中已安装的 .gram 模型，按文件名排序
+func ListModels(targetDir string) ([]ModelInfo, error) {
+	targetDir = system.ExpandHomeDir(targetDir)
+	entries, err := os.ReadDir(targetDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var models []ModelInfo
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(strings.ToLower(entry.Name()), ".gram") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		models = append(models, ModelInfo{Name: entry.Name(), Size: info.Size(), ModTime: info.ModTime()})
+	}
+	sort.Slice(models, func(i, j int) bool {
+		return models[i].Name < models[j].Name
+	})
+	return models, nil
+}
@@ -0,0 +1,75 @@
+package updater
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateModelName(t *testing.T) {
+	valid := []string{"wanxiang-lts-zh-hant.gram", "custom.GRAM"}
+	for _, name := range valid {
+		if err := ValidateModelName(name); err != nil {
+			t.Errorf("ValidateModelName(%q) = %v; want nil", name, err)
+		}
+	}
+
+	invalid := []string{"", "..", "model.bin", "../model.gram", `dir\model.gram`, "dir/model.gram"}
+	for _, name := range invalid {
+		if err := ValidateModelName(name); err == nil {
+			t.Errorf("ValidateModelName(%q) = nil; want error", name)
+		}
+	}
+}
+
+func TestUpdateModelUsesModelName(t *testing.T) {
+	targetDir := t.TempDir()
+	if err := UpdateModel(testGram("hans"), targetDir); err != nil {
+		t.Fatalf("UpdateModel failed: %v", err)
+	}
+	if err := UpdateModelWithOptions(testGram("hant"), targetDir, Options{ModelName: "wanxiang-lts-zh-hant.gram"}); err != nil {
+		t.Fatalf("UpdateModelWithOptions failed: %v", err)
+	}
+
+	for name, want := range map[string]string{DefaultModelName: string(testGram("hans")), "wanxiang-lts-zh-hant.gram": string(testGram("hant"))} {
+		data, err := os.ReadFile(filepath.Join(targetDir, name))
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		if string(data) != want {
+			t.Errorf("%s differs from the installed model", name)
+		}
+	}
+
+	if err := UpdateModelWithOptions(testGram("x"), targetDir, Options{ModelName: "../escape.gram"}); err == nil {
+		t.Fatal("UpdateModelWithOptions accepted a path outside the target")
+	}
+}
+
+func TestListModels(t *testing.T) {
+	targetDir := t.TempDir()
+	for _, name := range []string{"b.gram", "a.gram", "default.yaml"} {
+		if err := os.WriteFile(filepath.Join(targetDir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(targetDir, "dir.gram"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	models, err := ListModels(targetDir)
+	if err != nil {
+		t.Fatalf("ListModels failed: %v", err)
+	}
+	if len(models) != 2 || models[0].Name != "a.gram" || models[1].Name != "b.gram" {
+		t.Fatalf("ListModels = %+v; want a.gram, b.gram", models)
+	}
+	if models[0].Size != int64(len("a.gram")) {
+		t.Errorf("size = %d; want %d", models[0].Size, len("a.gram"))
+	}
+
+	missing, err := ListModels(filepath.Join(targetDir, "missing"))
+	if err != nil || missing != nil {
+		t.Fatalf("ListModels(missing) = %v, %v; want nil, nil", missing, err)
+	}
+}
@@ -0,0 +1,95 @@
+package updater
+
+import (
+	"archive/zip"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+// zip 文件名编码，NameEncodingAuto 为默认值
+const (
+	NameEncodingAuto     = ""
+	NameEncodingUTF8     = "utf-8"
+	NameEncodingGB18030  = "gb18030"
+	NameEncodingBig5     = "big5"
+	NameEncodingShiftJIS = "shift-jis"
+)
+
+var nameEncodings = map[string]encoding.Encoding{
+	NameEncodingGB18030:  simplifiedchinese.GB18030,
+	"gbk":                simplifiedchinese.GB18030,
+	"gb2312":             simplifiedchinese.GB18030,
+	NameEncodingBig5:     traditionalchinese.Big5,
+	NameEncodingShiftJIS: japanese.ShiftJIS,
+	"sjis":               japanese.ShiftJIS,
+}
+
+// 自动检测时依次尝试的编码：GB18030 兼容 GBK，覆盖绝大多数中文 Windows 打包的 zip
+var autoNameEncodings = []string{NameEncodingGB18030, NameEncodingBig5, NameEncodingShiftJIS}
+
+// ValidateNameEncoding 检查用户指定的 zip 文件名编码是否受支持
+func ValidateNameEncoding(name string) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == NameEncodingAuto || name == "auto" || name == NameEncodingUTF8 || name == "utf8" {
+		return nil
+	}
+	if _, ok := nameEncodings[name]; ok {
+		return nil
+	}
+	return fmt.Errorf("不支持的文件名编码: %s（可选 auto、utf-8、gbk、gb18030、big5、shift-jis）", name)
+}
+
+// decodeNames 将未标记 UTF-8 的 zip 条目名转换为 UTF-8。
+// 自动模式只处理不是合法 UTF-8 的文件名；指定编码时处理所有未设置 UTF-8 标志的条目
+func decodeNames(files []*zip.File, encodingName string) error {
+	encodingName = strings.ToLower(strings.TrimSpace(encodingName))
+	if err := ValidateNameEncoding(encodingName); err != nil {
+		return err
+	}
+	if encodingName == NameEncodingUTF8 || encodingName == "utf8" {
+		return nil
+	}
+	auto := encodingName == NameEncodingAuto || encodingName == "auto"
+
+	for _, file := range files {
+		if auto {
+			if utf8.ValidString(file.Name) {
+				continue
+			}
+			decoded, ok := decodeNameAuto(file.Name)
+			if !ok {
+				return invalidPackage("无法识别 zip 文件名编码: %q，请手动指定编码", file.Name)
+			}
+			file.Name = decoded
+			continue
+		}
+
+		// 设置了 UTF-8 标志的条目不做转换
+		if file.Flags&0x800 != 0 {
+			continue
+		}
+		decoded, err := nameEncodings[encodingName].NewDecoder().String(file.Name)
+		if err != nil {
+			return fmt.Errorf("按 %s 解码 zip 文件名失败 %q: %v", encodingName, file.Name, err)
+		}
+		file.Name = decoded
+	}
+	return nil
+}
+
+// decodeNameAuto 依次尝试候选编码，返回第一个不含无法解码字符的结果
+func decodeNameAuto(name string) (string, bool) {
+	for _, candidate := range autoNameEncodings {
+		decoded, err := nameEncodings[candidate].NewDecoder().String(name)
+		if err == nil && utf8.ValidString(decoded) && !strings.ContainsRune(decoded, utf8.RuneError) {
+			return decoded, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,109 @@
+package updater
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+// legacyZip 生成文件名使用旧编码且未设置 UTF-8 标志的 zip
+func legacyZip(t *testing.T, enc encoding.Encoding, names ...string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range names {
+		encoded, err := enc.NewEncoder().String(name)
+		if err != nil {
+			t.Fatalf("encode name %q: %v", name, err)
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: encoded, Method: zip.Deflate, NonUTF8: true})
+		if err != nil {
+			t.Fatalf("create zip entry: %v", err)
+		}
+		if _, err := w.Write([]byte(name)); err != nil {
+			t.Fatalf("write zip entry: %v", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestUpdateMainSchemeDecodesGBKNames(t *testing.T) {
+	targetDir := filepath.Join(t.TempDir(), "Rime")
+
+	data := legacyZip(t, simplifiedchinese.GBK, "新建.txt", "词库/自定义.dict.yaml")
+	if err := UpdateMainScheme(data, targetDir); err != nil {
+		t.Fatalf("UpdateMainScheme returned error: %v", err)
+	}
+
+	for _, name := range []string{"新建.txt", filepath.Join("词库", "自定义.dict.yaml")} {
+		if _, err := os.Stat(filepath.Join(targetDir, name)); err != nil {
+			t.Fatalf("decoded file %s missing: %v", name, err)
+		}
+	}
+}
+
+func TestDecodeNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		enc      encoding.Encoding
+		original string
+		force    string
+		want     string
+	}{
+		{name: "auto gbk", enc: simplifiedchinese.GBK, original: "薄荷拼音.schema.yaml", want: "薄荷拼音.schema.yaml"},
+		{name: "forced gbk", enc: simplifiedchinese.GBK, original: "新建.txt", force: "gbk", want: "新建.txt"},
+		{name: "forced big5", enc: traditionalchinese.Big5, original: "倉頡.schema.yaml", force: "big5", want: "倉頡.schema.yaml"},
+		{name: "ascii unchanged", enc: simplifiedchinese.GBK, original: "default.yaml", force: "gbk", want: "default.yaml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := legacyZip(t, tt.enc, tt.original)
+			zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				t.Fatalf("read zip: %v", err)
+			}
+			if err := decodeNames(zipReader.File, tt.force); err != nil {
+				t.Fatalf("decodeNames returned error: %v", err)
+			}
+			if got := zipReader.File[0].Name; got != tt.want {
+				t.Fatalf("decoded name = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeNamesKeepsUTF8Names(t *testing.T) {
+	data := testZip(t, zipEntry{name: "万象.gram", body: "model"})
+	zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("read zip: %v", err)
+	}
+	if err := decodeNames(zipReader.File, "gbk"); err != nil {
+		t.Fatalf("decodeNames returned error: %v", err)
+	}
+	if got := zipReader.File[0].Name; got != "万象.gram" {
+		t.Fatalf("name = %q; want 万象.gram", got)
+	}
+}
+
+func TestValidateNameEncoding(t *testing.T) {
+	for _, name := range []string{"", "auto", "UTF-8", "gbk", "GB18030", "big5", "shift-jis"} {
+		if err := ValidateNameEncoding(name); err != nil {
+			t.Fatalf("ValidateNameEncoding(%q) returned error: %v", name, err)
+		}
+	}
+	if err := ValidateNameEncoding("latin1"); err == nil {
+		t.Fatal("ValidateNameEncoding(latin1) returned nil; want error")
+	}
+}
@@ -0,0 +1,44 @@
+package updater
+
+import (
+	"archive/zip"
+	"os"
+	"time"
+)
+
+// 解压后的默认权限，避免 Windows 上打包的 zip 带来 0 权限或多余的可执行位
+const (
+	defaultFileMode os.FileMode = 0644
+	defaultDirMode  os.FileMode = 0755
+)
+
+// fileMode 返回解压后普通文件的权限
+func (o Options) fileMode(file *zip.File) os.FileMode {
+	if o.KeepArchiveModes {
+		// 保留 zip 中的权限，但权限为 0 时无法读取，仍使用默认值
+		if perm := file.Mode().Perm(); perm != 0 {
+			return perm
+		}
+	}
+	return defaultFileMode
+}
+
+// dirMode 返回解压后目录的权限
+func (o Options) dirMode(file *zip.File) os.FileMode {
+	if o.KeepArchiveModes {
+		// 目录缺少所有者的执行位时无法进入，仍使用默认值
+		if perm := file.Mode().Perm(); perm&0500 == 0500 {
+			return perm
+		}
+	}
+	return defaultDirMode
+}
+
+// applyModTime 保留 zip 条目的修改时间，使 Rime 部署时的变更检测结果可预期
+func applyModTime(file *zip.File, targetPath string) error {
+	modTime := file.Modified
+	if modTime.IsZero() {
+		return nil
+	}
+	return os.Chtimes(targetPath, time.Now(), modTime)
+}
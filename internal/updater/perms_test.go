@@ -0,0 +1,81 @@
+package updater
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestUpdateMainSchemeNormalizesModes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows 不支持 Unix 权限位")
+	}
+	targetDir := filepath.Join(t.TempDir(), "Rime")
+
+	err := UpdateMainScheme(testZip(t,
+		zipEntry{name: "lua/", mode: os.ModeDir},
+		zipEntry{name: "lua/helper.lua", body: "return {}"},
+		zipEntry{name: "rime_mint.schema.yaml", body: "schema:", mode: 0777},
+	), targetDir)
+	if err != nil {
+		t.Fatalf("UpdateMainScheme returned error: %v", err)
+	}
+
+	assertMode(t, filepath.Join(targetDir, "lua"), defaultDirMode)
+	assertMode(t, filepath.Join(targetDir, "lua", "helper.lua"), defaultFileMode)
+	assertMode(t, filepath.Join(targetDir, "rime_mint.schema.yaml"), defaultFileMode)
+}
+
+func TestUpdateMainSchemeKeepsArchiveModes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows 不支持 Unix 权限位")
+	}
+	targetDir := filepath.Join(t.TempDir(), "Rime")
+
+	err := UpdateMainSchemeWithOptions(testZip(t,
+		zipEntry{name: "private.yaml", body: "secret", mode: 0600},
+		zipEntry{name: "scripts/", mode: os.ModeDir | 0700},
+		zipEntry{name: "broken/", mode: os.ModeDir | 0644},
+	), targetDir, Options{KeepArchiveModes: true})
+	if err != nil {
+		t.Fatalf("UpdateMainSchemeWithOptions returned error: %v", err)
+	}
+
+	assertMode(t, filepath.Join(targetDir, "private.yaml"), 0600)
+	assertMode(t, filepath.Join(targetDir, "scripts"), 0700)
+	// 不可进入的目录仍使用默认权限
+	assertMode(t, filepath.Join(targetDir, "broken"), defaultDirMode)
+}
+
+func TestUpdateMainSchemeKeepsModificationTimes(t *testing.T) {
+	targetDir := filepath.Join(t.TempDir(), "Rime")
+	modified := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+
+	err := UpdateMainScheme(testZip(t,
+		zipEntry{name: "default.yaml", body: "config_version: 1", modified: modified},
+	), targetDir)
+	if err != nil {
+		t.Fatalf("UpdateMainScheme returned error: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(targetDir, "default.yaml"))
+	if err != nil {
+		t.Fatalf("stat extracted file: %v", err)
+	}
+	if !info.ModTime().Equal(modified) {
+		t.Fatalf("mod time = %v; want %v", info.ModTime().UTC(), modified)
+	}
+}
+
+func assertMode(t *testing.T, path string, want os.FileMode) {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat %s: %v", path, err)
+	}
+	if got := info.Mode().Perm(); got != want {
+		t.Fatalf("%s mode = %o; want %o", path, got, want)
+	}
+}
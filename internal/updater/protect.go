@@ -0,0 +1,35 @@
+package updater
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// filterProtected 跳过目标目录中已存在且匹配 Options.Protected 的文件，避免覆盖用户修改过的配置。
+// 规则相对于目标目录，不含目录的规则同时匹配文件名
+func filterProtected(files []*zip.File, targetDir string, patterns []string) []*zip.File {
+	if len(patterns) == 0 {
+		return files
+	}
+
+	var kept []*zip.File
+	for _, file := range files {
+		if !file.FileInfo().IsDir() && matchAnyGlob(patterns, file.Name) {
+			if _, err := os.Lstat(filepath.Join(targetDir, filepath.FromSlash(file.Name))); err == nil {
+				fmt.Printf("保留受保护的文件: %s\n", file.Name)
+				continue
+			}
+		}
+		kept = append(kept, file)
+	}
+	return kept
+}
+
+func (o Options) backupKeep() int {
+	if o.BackupKeep > 0 {
+		return o.BackupKeep
+	}
+	return backupKeepCount
+}
@@ -0,0 +1,45 @@
+package updater
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUpdateMainSchemeKeepsProtectedFiles(t *testing.T) {
+	targetDir := t.TempDir()
+	protectedPath := filepath.Join(targetDir, "default.custom.yaml")
+	if err := os.WriteFile(protectedPath, []byte("mine"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := UpdateMainSchemeWithOptions(testZip(t,
+		zipEntry{name: "default.custom.yaml", body: "upstream"},
+		zipEntry{name: "squirrel.custom.yaml", body: "upstream"},
+		zipEntry{name: "default.yaml", body: "upstream"},
+	), targetDir, Options{Protected: []string{"*.custom.yaml"}, NoBackup: true})
+	if err != nil {
+		t.Fatalf("UpdateMainSchemeWithOptions returned error: %v", err)
+	}
+
+	// 已存在的受保护文件保持不变，不存在的仍会安装
+	want := map[string]string{
+		"default.custom.yaml":  "mine",
+		"squirrel.custom.yaml": "upstream",
+		"default.yaml":         "upstream",
+	}
+	for name, body := range want {
+		if data, err := os.ReadFile(filepath.Join(targetDir, name)); err != nil || string(data) != body {
+			t.Errorf("%s = %q, %v; want %q", name, data, err, body)
+		}
+	}
+}
+
+func TestBackupKeepDefault(t *testing.T) {
+	if got := (Options{}).backupKeep(); got != backupKeepCount {
+		t.Errorf("backupKeep() = %d; want %d", got, backupKeepCount)
+	}
+	if got := (Options{BackupKeep: 7}).backupKeep(); got != 7 {
+		t.Errorf("backupKeep() = %d; want 7", got)
+	}
+}
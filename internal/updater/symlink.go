@@ -0,0 +1,166 @@
+package updater
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SymlinkPolicy zip 中符号链接条目的处理方式
+type SymlinkPolicy int
+
+const (
+	// SymlinkWithinTarget 仅当链接解析后仍位于目标目录内时创建符号链接，否则拒绝
+	SymlinkWithinTarget SymlinkPolicy = iota
+	// SymlinkReject 拒绝任何符号链接条目
+	SymlinkReject
+)
+
+// 符号链接条目的内容是链接目标路径，超过该长度视为异常
+const maxSymlinkTargetLen = 4096
+
+func isSymlinkEntry(file *zip.File) bool {
+	return file.Mode()&os.ModeSymlink != 0
+}
+
+// extractSymlink 按策略将 zip 中的符号链接条目创建为符号链接
+func extractSymlink(file *zip.File, targetPath, rootDir string, policy SymlinkPolicy) error {
+	if policy == SymlinkReject {
+		return invalidPackage("zip 包含符号链接，已拒绝: %s", file.Name)
+	}
+
+	rc, err := file.Open()
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(io.LimitReader(rc, maxSymlinkTargetLen+1))
+	rc.Close()
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 || len(data) > maxSymlinkTargetLen {
+		return invalidPackage("符号链接目标无效: %s", file.Name)
+	}
+
+	linkTarget := filepath.FromSlash(string(data))
+	if err := checkSymlinkTarget(targetPath, linkTarget, rootDir); err != nil {
+		return invalidPackage("%v: %s -> %s", err, file.Name, string(data))
+	}
+
+	// 覆盖同名文件或链接
+	if err := os.Remove(targetPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Symlink(linkTarget, targetPath)
+}
+
+// pendingLink 等待创建的符号链接条目及其目标路径
+type pendingLink struct {
+	file *zip.File
+	path string
+}
+
+// extractSymlinks 在其余文件写入后依次创建符号链接，链接必须位于 rootDir 内
+func extractSymlinks(links []pendingLink, rootDir string, j *journal, opts Options) error {
+	for _, link := range links {
+		if err := checkRealParent(rootDir, link.path); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(link.path), 0755); err != nil {
+			return fmt.Errorf("创建父目录失败: %v", err)
+		}
+		j.record(link.path)
+		if err := extractSymlink(link.file, link.path, rootDir, opts.Symlinks); err != nil {
+			fmt.Printf("创建符号链接失败 %s: %v\n", link.path, err)
+			return err
+		}
+		fmt.Printf("创建符号链接: %s\n", link.path)
+		opts.emit(Event{Type: EventFileWritten, Path: link.path})
+	}
+	return nil
+}
+
+// checkSymlinkTarget 检查链接解析后是否位于 rootDir 内。链接目标不能包含 ..，且必须指向已存在的路径；
+// 链接所在目录与链接目标都按真实路径解析，防止借助之前创建的链接跳出目标目录
+func checkSymlinkTarget(linkPath, linkTarget, rootDir string) error {
+	if filepath.IsAbs(linkTarget) || filepath.VolumeName(linkTarget) != "" {
+		return fmt.Errorf("符号链接指向绝对路径")
+	}
+	for _, part := range strings.Split(filepath.ToSlash(linkTarget), "/") {
+		if part == ".." {
+			return fmt.Errorf("符号链接目标包含 ..")
+		}
+	}
+
+	realRoot, err := filepath.EvalSymlinks(rootDir)
+	if err != nil {
+		return err
+	}
+	realDir, err := filepath.EvalSymlinks(filepath.Dir(linkPath))
+	if err != nil {
+		return err
+	}
+	if !isWithinDir(realRoot, realDir) {
+		return fmt.Errorf("符号链接所在目录位于目标目录之外")
+	}
+
+	resolved, err := filepath.EvalSymlinks(filepath.Join(realDir, linkTarget))
+	if err != nil {
+		return fmt.Errorf("符号链接指向不存在的路径")
+	}
+	if !isWithinDir(realRoot, resolved) {
+		return fmt.Errorf("符号链接指向目标目录之外")
+	}
+	return nil
+}
+
+// checkRealParent 写入 path 前检查其所在目录（或最近的已存在上级目录）按真实路径解析后仍位于 rootDir 内，
+// 防止经由目标目录中的符号链接写到目标目录之外
+func checkRealParent(rootDir, path string) error {
+	realRoot, err := filepath.EvalSymlinks(rootDir)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	for {
+		if _, err := os.Lstat(dir); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+	if !isWithinDir(realRoot, realDir) {
+		return invalidPackage("解压路径经符号链接指向目标目录之外: %s", path)
+	}
+	return nil
+}
+
+func isWithinDir(dir, path string) bool {
+	relPath, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return relPath != ".." && !strings.HasPrefix(relPath, ".."+string(os.PathSeparator))
+}
+
+// copySymlink 复制符号链接本身，不跟随链接
+func copySymlink(src, dst string) error {
+	linkTarget, err := os.Readlink(src)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Symlink(linkTarget, dst)
+}
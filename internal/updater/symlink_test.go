@@ -0,0 +1,168 @@
+package updater
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func skipWithoutSymlinks(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("创建符号链接在 Windows 上需要额外权限")
+	}
+}
+
+func TestUpdateMainSchemeCreatesSymlinkInsideTarget(t *testing.T) {
+	skipWithoutSymlinks(t)
+	targetDir := filepath.Join(t.TempDir(), "Rime")
+
+	err := UpdateMainScheme(testZip(t,
+		zipEntry{name: "lua/real.lua", body: "return {}"},
+		zipEntry{name: "lua/alias.lua", body: "real.lua", mode: os.ModeSymlink | 0777},
+	), targetDir)
+	if err != nil {
+		t.Fatalf("UpdateMainScheme returned error: %v", err)
+	}
+
+	linkPath := filepath.Join(targetDir, "lua", "alias.lua")
+	if target, err := os.Readlink(linkPath); err != nil || target != "real.lua" {
+		t.Fatalf("symlink target = %q, %v; want real.lua", target, err)
+	}
+}
+
+func TestUpdateMainSchemeRejectsEscapingSymlinks(t *testing.T) {
+	skipWithoutSymlinks(t)
+
+	tests := []struct {
+		name    string
+		entries []zipEntry
+		opts    Options
+	}{
+		{
+			name:    "parent",
+			entries: []zipEntry{{name: "escape", body: "../outside", mode: os.ModeSymlink | 0777}},
+		},
+		{
+			name:    "absolute",
+			entries: []zipEntry{{name: "escape", body: "/etc/passwd", mode: os.ModeSymlink | 0777}},
+		},
+		{
+			// 第一个链接指向目标目录自身，第二个链接借助它跳出目标目录
+			name: "chained",
+			entries: []zipEntry{
+				{name: "self", body: ".", mode: os.ModeSymlink | 0777},
+				{name: "self/escape", body: "../outside", mode: os.ModeSymlink | 0777},
+			},
+		},
+		{
+			name:    "reject policy",
+			entries: []zipEntry{{name: "alias", body: "real.yaml", mode: os.ModeSymlink | 0777}},
+			opts:    Options{Symlinks: SymlinkReject},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parentDir := t.TempDir()
+			targetDir := filepath.Join(parentDir, "Rime")
+			if err := os.MkdirAll(targetDir, 0755); err != nil {
+				t.Fatalf("create target dir: %v", err)
+			}
+
+			if err := UpdateMainSchemeWithOptions(testZip(t, tt.entries...), targetDir, tt.opts); err == nil {
+				t.Fatal("UpdateMainSchemeWithOptions returned nil; want symlink error")
+			}
+			entries, err := os.ReadDir(targetDir)
+			if err != nil {
+				t.Fatalf("read target dir: %v", err)
+			}
+			if len(entries) != 0 {
+				t.Fatalf("target dir has %d entries after rollback; want 0", len(entries))
+			}
+		})
+	}
+}
+
+func TestBackupKeepsSymlinksWithoutFollowing(t *testing.T) {
+	skipWithoutSymlinks(t)
+	parentDir := t.TempDir()
+	targetDir := filepath.Join(parentDir, "Rime")
+	cloudDir := filepath.Join(parentDir, "Dropbox", "rime-sync")
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		t.Fatalf("create target dir: %v", err)
+	}
+	if err := os.MkdirAll(cloudDir, 0755); err != nil {
+		t.Fatalf("create cloud dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(cloudDir, "user.yaml"), []byte("cloud"), 0644); err != nil {
+		t.Fatalf("write cloud file: %v", err)
+	}
+	if err := os.Symlink(cloudDir, filepath.Join(targetDir, "sync")); err != nil {
+		t.Fatalf("create sync symlink: %v", err)
+	}
+
+	backupDir, hasBackup, err := createBackup(targetDir)
+	if err != nil || !hasBackup {
+		t.Fatalf("createBackup = %v, %v; want backup", hasBackup, err)
+	}
+
+	info, err := os.Lstat(filepath.Join(backupDir, "sync"))
+	if err != nil {
+		t.Fatalf("lstat backup sync: %v", err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("backup sync mode = %v; want symlink", info.Mode())
+	}
+
+	// 恢复备份后链接依旧指向原位置
+	if err := restoreBackup(targetDir, backupDir); err != nil {
+		t.Fatalf("restoreBackup returned error: %v", err)
+	}
+	if target, err := os.Readlink(filepath.Join(targetDir, "sync")); err != nil || target != cloudDir {
+		t.Fatalf("restored symlink = %q, %v; want %s", target, err, cloudDir)
+	}
+	if data, err := os.ReadFile(filepath.Join(cloudDir, "user.yaml")); err != nil || string(data) != "cloud" {
+		t.Fatalf("cloud file after restore = %q, %v; want cloud", data, err)
+	}
+}
+
+func TestUpdateMainSchemeRejectsSymlinkChainEscape(t *testing.T) {
+	skipWithoutSymlinks(t)
+	parentDir := t.TempDir()
+	targetDir := filepath.Join(parentDir, "Rime")
+
+	// x -> y/..，y -> .，再经由 x 写入文件：按字面拼接 x 仍在目标目录内，实际指向其上级目录
+	err := UpdateMainScheme(testZip(t,
+		zipEntry{name: "x", body: "y/..", mode: os.ModeSymlink | 0777},
+		zipEntry{name: "y", body: ".", mode: os.ModeSymlink | 0777},
+		zipEntry{name: "x/evil.txt", body: "evil"},
+	), targetDir)
+	if err == nil {
+		t.Fatal("UpdateMainScheme returned nil; want symlink error")
+	}
+	if _, err := os.Stat(filepath.Join(parentDir, "evil.txt")); !os.IsNotExist(err) {
+		t.Fatalf("evil.txt was written outside the target dir: %v", err)
+	}
+}
+
+func TestExtractFileRejectsSymlinkedParent(t *testing.T) {
+	skipWithoutSymlinks(t)
+	parentDir := t.TempDir()
+	targetDir := filepath.Join(parentDir, "Rime")
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(parentDir, filepath.Join(targetDir, "out")); err != nil {
+		t.Fatal(err)
+	}
+
+	err := UpdateMainScheme(testZip(t, zipEntry{name: "out/evil.txt", body: "evil"}), targetDir)
+	if err == nil {
+		t.Fatal("UpdateMainScheme returned nil; want error for a parent outside the target")
+	}
+	if _, err := os.Stat(filepath.Join(parentDir, "evil.txt")); !os.IsNotExist(err) {
+		t.Fatalf("evil.txt was written outside the target dir: %v", err)
+	}
+}
@@ -8,39 +8,107 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"oh-my-rime-cli/internal/system"
 )
 
-
 const backupKeepCount = 3
 
+// Options 更新选项
+type Options struct {
+	// Source 更新包来源 URL，记录在更新日志中以便中断后继续
+	Source string
+	// LockWait 目标目录被其他进程锁定时的最长等待时间，0 表示立即返回错误
+	LockWait time.Duration
+	// Limits 解压资源限制，nil 时使用 DefaultExtractLimits
+	Limits *ExtractLimits
+	// Symlinks zip 中符号链接条目的处理方式
+	Symlinks SymlinkPolicy
+	// KeepArchiveModes 保留 zip 中记录的文件权限，默认文件统一为 0644、目录为 0755
+	KeepArchiveModes bool
+	// NameEncoding zip 文件名编码，默认自动识别未标记 UTF-8 的 GBK/GB18030 等编码
+	NameEncoding string
+	// AllowNameCollisions 在 macOS/Windows 上遇到大小写或 Unicode 规范化冲突时仍继续解压
+	AllowNameCollisions bool
+	// DictSelection 本次安装的词库选择，设置后会保存供以后更新使用；nil 时沿用已保存的选择
+	DictSelection *DictSelection
+	// DictSync 词库同步方式，默认 merge
+	DictSync DictSyncMode
+	// DictKeep 镜像模式下保留的本地词库匹配规则，nil 时使用 DefaultDictKeepPatterns
+	DictKeep []string
+	// ModelName 模型更新写入的文件名，为空时使用 DefaultModelName
+	ModelName string
+	// Hooks 更新前后执行的钩子，nil 时不执行
+	Hooks *Hooks
+	// Version 资源版本，传给钩子并记录在目标目录中；为空时从 Source 推断
+	Version string
+	// ETag 下载时服务器返回的 ETag，记录后用于检查更新
+	ETag string
+	// ReleaseDate 资源的发布时间，记录后用于显示
+	ReleaseDate time.Time
+	// NoBackup 不创建备份，更新失败时无法恢复到更新前状态
+	NoBackup bool
+	// Events 接收备份、写入文件、回滚等事件，nil 时不发送
+	Events EventCallback
+	// Protected 目标目录中已存在时不会被覆盖的文件匹配规则，镜像模式下也不会被删除
+	Protected []string
+	// BackupKeep 保留的备份数量，0 时使用默认值 3
+	BackupKeep int
+
+	resume *PendingUpdate
+}
+
 // UpdateMainScheme 更新主方案
 func UpdateMainScheme(rimeZip []byte, targetDir string) error {
+	return UpdateMainSchemeWithOptions(rimeZip, targetDir, Options{})
+}
+
+// UpdateMainSchemeWithOptions 带选项的主方案更新
+func UpdateMainSchemeWithOptions(rimeZip []byte, targetDir string, opts Options) error {
 	targetDir = system.ExpandHomeDir(targetDir)
 	fmt.Println("正在更新主方案...")
 
 	// 检查zip数据是否有效
 	if rimeZip == nil || len(rimeZip) == 0 {
-		return fmt.Errorf("zip数据无效")
+		return opts.failBeforeUpdate(OperationMainScheme, targetDir, invalidPackage("zip数据无效"))
+	}
+
+	// 从字节数组创建zip reader
+	zipReader, err := zip.NewReader(bytes.NewReader(rimeZip), int64(len(rimeZip)))
+	if err != nil {
+		return opts.failBeforeUpdate(OperationMainScheme, targetDir, invalidPackage("读取zip文件失败: %v", err))
+	}
+
+	// Windows 资源管理器等工具打包的 zip 文件名可能是 GBK 编码
+	if err := decodeNames(zipReader.File, opts.NameEncoding); err != nil {
+		return opts.failBeforeUpdate(OperationMainScheme, targetDir, err)
+	}
+
+	// 按保存的词库选择跳过不需要的词库
+	selection, err := resolveDictSelection(targetDir, opts)
+	if err != nil {
+		return opts.failBeforeUpdate(OperationMainScheme, targetDir, err)
+	}
+	files := filterProtected(filterDicts(zipReader.File, selection), targetDir, opts.Protected)
+
+	// 修改目标目录前检查文件名冲突、解压限制和磁盘空间
+	budget, err := prepareExtraction(files, targetDir, opts)
+	if err != nil {
+		return opts.failBeforeUpdate(OperationMainScheme, targetDir, err)
 	}
 
-	return runWithBackup("主方案更新", targetDir, func() error {
+	return runWithBackup(OperationMainScheme, targetDir, opts, func(j *journal) error {
 		// 创建目标目录（如果不存在）
 		if err := os.MkdirAll(targetDir, 0755); err != nil {
 			return fmt.Errorf("创建目标目录失败: %v", err)
 		}
 
-		// 从字节数组创建zip reader
-		zipReader, err := zip.NewReader(bytes.NewReader(rimeZip), int64(len(rimeZip)))
-		if err != nil {
-			return fmt.Errorf("读取zip文件失败: %v", err)
-		}
-
 		// 遍历zip文件中的每个文件
-		for _, file := range zipReader.File {
+		var links []pendingLink
+		for _, file := range files {
 			// 构建目标文件路径
 			targetPath, err := safeJoin(targetDir, file.Name)
 			if err != nil {
@@ -49,27 +117,30 @@ func UpdateMainScheme(rimeZip []byte, targetDir string) error {
 
 			if file.FileInfo().IsDir() {
 				// 创建目录
-				if err := os.MkdirAll(targetPath, file.FileInfo().Mode()); err != nil {
+				if err := makeDir(targetDir, targetPath, opts.dirMode(file)); err != nil {
 					fmt.Printf("创建目录失败 %s: %v\n", targetPath, err)
 					return err
 				}
 				fmt.Printf("创建目录: %s\n", targetPath)
+			} else if isSymlinkEntry(file) {
+				// 符号链接在所有文件写入后再创建，解压过程中不会经由包内的链接写入文件
+				links = append(links, pendingLink{file: file, path: targetPath})
 			} else {
-				// 创建父目录
-				if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
-					fmt.Printf("创建父目录失败 %s: %v\n", filepath.Dir(targetPath), err)
-					return err
-				}
-
-				// 解压文件
-				if err := extractFile(file, targetPath); err != nil {
+				// 解压文件（先记录到更新日志，中断时可回滚未完成的文件）
+				j.record(targetPath)
+				if err := extractFile(file, targetDir, targetPath, opts.fileMode(file), budget); err != nil {
 					fmt.Printf("解压文件失败 %s: %v\n", targetPath, err)
 					return err
 				}
 				fmt.Printf("解压文件: %s\n", targetPath)
+				opts.emit(Event{Type: EventFileWritten, Path: targetPath})
 			}
 		}
+		if err := extractSymlinks(links, targetDir, j, opts); err != nil {
+			return err
+		}
 
+		opts.recordVersion(targetDir, OperationMainScheme, rimeZip)
 		fmt.Println("✅ 主方案更新完成！")
 		return nil
 	})
@@ -77,66 +148,106 @@ func UpdateMainScheme(rimeZip []byte, targetDir string) error {
 
 // UpdateModel 更新模型文件
 func UpdateModel(rimeGram []byte, targetDir string) error {
+	return UpdateModelWithOptions(rimeGram, targetDir, Options{})
+}
+
+// UpdateModelWithOptions 带选项的模型更新
+func UpdateModelWithOptions(rimeGram []byte, targetDir string, opts Options) error {
 	targetDir = system.ExpandHomeDir(targetDir)
 	fmt.Println("正在更新模型...")
 
 	// 检查模型数据是否有效
 	if rimeGram == nil || len(rimeGram) == 0 {
-		return fmt.Errorf("模型数据无效")
+		return opts.failBeforeUpdate(OperationModel, targetDir, invalidPackage("模型数据无效"))
+	}
+
+	modelName := opts.modelName()
+	if err := ValidateModelName(modelName); err != nil {
+		return opts.failBeforeUpdate(OperationModel, targetDir, err)
+	}
+	// 校验通过后才会备份和替换，损坏的下载不会覆盖原有模型
+	if err := ValidateModel(rimeGram); err != nil {
+		return opts.failBeforeUpdate(OperationModel, targetDir, err)
 	}
 
-	return runWithBackup("模型更新", targetDir, func() error {
+	return runWithBackup(OperationModel, targetDir, opts, func(j *journal) error {
 		if err := os.MkdirAll(targetDir, 0755); err != nil {
 			return fmt.Errorf("创建目标目录失败: %v", err)
 		}
 
-		// 覆盖保存目录内的模型文件
-		modelPath := filepath.Join(targetDir, "wanxiang-lts-zh-hans.gram")
-		if err := os.WriteFile(modelPath, rimeGram, 0644); err != nil {
+		// 覆盖保存目录内的同名模型文件
+		modelPath := filepath.Join(targetDir, modelName)
+		if err := writeFileAtomic(modelPath, rimeGram, 0644, j); err != nil {
 			return fmt.Errorf("更新模型失败: %v", err)
 		}
+		opts.emit(Event{Type: EventFileWritten, Path: modelPath})
 
-		fmt.Println("✅ 模型更新完成！")
+		opts.recordVersion(targetDir, ModelAsset(modelName), rimeGram)
+		fmt.Printf("✅ 模型更新完成！(%s)\n", modelName)
 		return nil
 	})
 }
 
 // UpdateDict 更新词库
 func UpdateDict(rimeZip []byte, targetDir string) error {
+	return UpdateDictWithOptions(rimeZip, targetDir, Options{})
+}
+
+// UpdateDictWithOptions 带选项的词库更新
+func UpdateDictWithOptions(rimeZip []byte, targetDir string, opts Options) error {
 	targetDir = system.ExpandHomeDir(targetDir)
 	fmt.Println("正在更新词库...")
 
 	// 检查zip数据是否有效
 	if rimeZip == nil || len(rimeZip) == 0 {
-		return fmt.Errorf("zip数据无效")
+		return opts.failBeforeUpdate(OperationDict, targetDir, invalidPackage("zip数据无效"))
+	}
+
+	// 从字节数组创建zip reader
+	zipReader, err := zip.NewReader(bytes.NewReader(rimeZip), int64(len(rimeZip)))
+	if err != nil {
+		return opts.failBeforeUpdate(OperationDict, targetDir, invalidPackage("读取zip文件失败: %v", err))
+	}
+
+	// Windows 资源管理器等工具打包的 zip 文件名可能是 GBK 编码
+	if err := decodeNames(zipReader.File, opts.NameEncoding); err != nil {
+		return opts.failBeforeUpdate(OperationDict, targetDir, err)
+	}
+
+	// 只处理dicts目录下的文件
+	var dictFiles []*zip.File
+	for _, file := range zipReader.File {
+		// 跳过dicts目录本身及目录外的文件
+		if strings.HasPrefix(file.Name, "dicts/") && file.Name != "dicts/" {
+			dictFiles = append(dictFiles, file)
+		}
+	}
+
+	selection, err := resolveDictSelection(targetDir, opts)
+	if err != nil {
+		return opts.failBeforeUpdate(OperationDict, targetDir, err)
+	}
+	dictFiles = filterProtected(filterDicts(dictFiles, selection), targetDir, opts.Protected)
+
+	// 修改目标目录前检查文件名冲突、解压限制和磁盘空间
+	budget, err := prepareExtraction(dictFiles, targetDir, opts)
+	if err != nil {
+		return opts.failBeforeUpdate(OperationDict, targetDir, err)
 	}
 
-	return runWithBackup("词库更新", targetDir, func() error {
+	return runWithBackup(OperationDict, targetDir, opts, func(j *journal) error {
 		// 创建目标词库目录
 		dictsTargetDir := filepath.Join(targetDir, "dicts")
 		if err := os.MkdirAll(dictsTargetDir, 0755); err != nil {
 			return fmt.Errorf("创建词库目录失败: %v", err)
 		}
 
-		// 从字节数组创建zip reader
-		zipReader, err := zip.NewReader(bytes.NewReader(rimeZip), int64(len(rimeZip)))
-		if err != nil {
-			return fmt.Errorf("读取zip文件失败: %v", err)
-		}
-
-		// 遍历zip文件中的每个文件，只处理dicts目录下的文件
-		for _, file := range zipReader.File {
-			// 检查文件是否在dicts目录下
-			if !strings.HasPrefix(file.Name, "dicts/") {
-				continue
-			}
-
+		installed := make(map[string]bool)
+		var links []pendingLink
+		for _, file := range dictFiles {
 			// 计算相对于dicts目录的路径
 			relativePath := strings.TrimPrefix(file.Name, "dicts/")
-			if relativePath == "" {
-				// 这是dicts目录本身，跳过
-				continue
-			}
+			installed[strings.TrimSuffix(relativePath, "/")] = true
 
 			// 构建目标文件路径
 			targetPath, err := safeJoin(dictsTargetDir, relativePath)
@@ -146,53 +257,138 @@ func UpdateDict(rimeZip []byte, targetDir string) error {
 
 			if file.FileInfo().IsDir() {
 				// 创建子目录
-				if err := os.MkdirAll(targetPath, file.FileInfo().Mode()); err != nil {
+				if err := makeDir(targetDir, targetPath, opts.dirMode(file)); err != nil {
 					fmt.Printf("创建词库子目录失败 %s: %v\n", targetPath, err)
 					return err
 				}
 				fmt.Printf("创建词库目录: %s\n", targetPath)
+			} else if isSymlinkEntry(file) {
+				// 符号链接在所有文件写入后再创建
+				links = append(links, pendingLink{file: file, path: targetPath})
 			} else {
-				// 创建父目录
-				if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
-					fmt.Printf("创建父目录失败 %s: %v\n", filepath.Dir(targetPath), err)
-					return err
-				}
-
-				// 解压文件
-				if err := extractFile(file, targetPath); err != nil {
+				// 解压文件（先记录到更新日志，中断时可回滚未完成的文件）
+				j.record(targetPath)
+				if err := extractFile(file, targetDir, targetPath, opts.fileMode(file), budget); err != nil {
 					fmt.Printf("解压词库文件失败 %s: %v\n", targetPath, err)
 					return err
 				}
 				fmt.Printf("更新词库文件: %s\n", targetPath)
+				opts.emit(Event{Type: EventFileWritten, Path: targetPath})
+			}
+		}
+		if err := extractSymlinks(links, targetDir, j, opts); err != nil {
+			return err
+		}
+
+		if opts.DictSync == DictSyncMirror {
+			if err := mirrorDicts(dictsTargetDir, installed, opts.dictKeepPatterns(), opts.Protected); err != nil {
+				return err
 			}
 		}
 
+		if opts.DictSelection != nil {
+			if err := SaveDictSelection(targetDir, opts.DictSelection); err != nil {
+				return fmt.Errorf("保存词库选择失败: %v", err)
+			}
+		}
+
+		opts.recordVersion(targetDir, OperationDict, rimeZip)
 		fmt.Println("✅ 词库更新完成！")
 		return nil
 	})
 }
 
-func runWithBackup(operationName, targetDir string, update func() error) error {
-	backupDir, hasBackup, err := createBackup(targetDir)
+func runWithBackup(operation, targetDir string, opts Options, update func(j *journal) error) error {
+	operationName := operationNames[operation]
+
+	// 同一目录同时只允许一个更新（GUI、CLI 或定时任务）
+	lock, err := acquireLock(targetDir, opts.LockWait)
 	if err != nil {
-		return fmt.Errorf("创建备份失败: %v", err)
+		return opts.failBeforeUpdate(operation, targetDir, err)
 	}
-	if hasBackup {
-		fmt.Printf("已创建备份: %s\n", backupDir)
+	defer lock.release()
+
+	var backupDir string
+	var hasBackup bool
+	var written []string
+	if opts.resume != nil {
+		// 继续中断的更新时沿用原有备份
+		backupDir = opts.resume.BackupDir
+		hasBackup = backupDir != ""
+		written = opts.resume.Written
+	} else if opts.NoBackup {
+		fmt.Println("已跳过备份，更新失败时将无法恢复")
+	} else {
+		backupDir, hasBackup, err = createBackup(targetDir)
+		if err != nil {
+			return opts.failBeforeUpdate(operation, targetDir, fmt.Errorf("创建备份失败: %v", err))
+		}
+		if hasBackup {
+			fmt.Printf("已创建备份: %s\n", backupDir)
+			opts.emit(Event{Type: EventBackupCreated, Path: backupDir})
+		}
+	}
+
+	env := HookEnv{
+		Operation: operation,
+		TargetDir: targetDir,
+		BackupDir: backupDir,
+		Source:    opts.Source,
+		Version:   opts.version(),
+	}
+	if err := opts.Hooks.Run(HookPreExtract, env); err != nil {
+		opts.runFailureHooks(env, err)
+		return fmt.Errorf("%w，已取消%s", err, operationName)
+	}
+
+	// 修改目标目录前写入更新日志，异常中断后可据此回滚或继续
+	j, err := beginJournal(PendingUpdate{
+		Operation: operation,
+		Source:    opts.Source,
+		TargetDir: targetDir,
+		BackupDir: backupDir,
+		StartedAt: time.Now(),
+		PID:       os.Getpid(),
+		ModelName: opts.ModelName,
+		Written:   written,
+	})
+	if err != nil {
+		return fmt.Errorf("写入更新日志失败: %v", err)
 	}
 
-	if err := update(); err != nil {
+	err = update(j)
+	if err == nil {
+		if hookErr := opts.Hooks.Run(HookPostExtract, env); hookErr != nil {
+			if opts.Hooks.RollbackOnPostFailure {
+				err = hookErr
+			} else {
+				fmt.Printf("⚠️  %v\n", hookErr)
+			}
+		}
+	}
+	if err != nil {
 		if hasBackup {
 			fmt.Printf("%s失败，正在恢复备份...\n", operationName)
 			if restoreErr := restoreBackup(targetDir, backupDir); restoreErr != nil {
-				return fmt.Errorf("%v；备份恢复失败: %v", err, restoreErr)
+				j.close()
+				err = fmt.Errorf("%w；%w: %v", err, ErrRollbackFailed, restoreErr)
+				opts.emit(Event{Type: EventRollbackFailed, Path: backupDir, Message: restoreErr.Error()})
+				opts.runFailureHooks(env, err)
+				return err
 			}
 			fmt.Println("已恢复到更新前状态")
+			opts.emit(Event{Type: EventRolledBack, Path: backupDir})
+			err = markError(err, ErrRolledBack)
+		} else if opts.NoBackup {
+			fmt.Printf("%s失败，未创建备份，目标目录可能处于部分更新的状态\n", operationName)
 		}
+		j.finish()
+		opts.runFailureHooks(env, err)
 		return err
 	}
+	j.finish()
 
-	if err := pruneBackups(targetDir, backupKeepCount); err != nil {
+	if err := pruneBackups(targetDir, opts.backupKeep()); err != nil {
 		fmt.Printf("清理旧备份失败: %v\n", err)
 	}
 	return nil
@@ -216,14 +412,40 @@ func createBackup(targetDir string) (string, bool, error) {
 		return "", false, err
 	}
 
-	backupDir := filepath.Join(backupRoot, time.Now().Format("20060102-150405"))
+	backupDir, err := uniqueBackupDir(backupRoot, time.Now())
+	if err != nil {
+		return "", false, err
+	}
 	if err := copyDir(targetDir, backupDir); err != nil {
 		return "", false, err
 	}
 	return backupDir, true, nil
 }
 
+// uniqueBackupDir 生成不与已有备份重名的目录，同一秒内的多次备份追加序号
+func uniqueBackupDir(backupRoot string, now time.Time) (string, error) {
+	base := now.Format("20060102-150405")
+	for i := 1; ; i++ {
+		name := base
+		if i > 1 {
+			name = fmt.Sprintf("%s-%d", base, i)
+		}
+		backupDir := filepath.Join(backupRoot, name)
+		err := os.Mkdir(backupDir, 0755)
+		if err == nil {
+			return backupDir, nil
+		}
+		if !os.IsExist(err) {
+			return "", err
+		}
+	}
+}
+
 func restoreBackup(targetDir, backupDir string) error {
+	// 目标目录本身是符号链接时恢复到链接指向的目录，保留链接
+	if realTarget, err := filepath.EvalSymlinks(targetDir); err == nil {
+		targetDir = realTarget
+	}
 	if err := os.RemoveAll(targetDir); err != nil {
 		return err
 	}
@@ -235,6 +457,11 @@ func backupRootDir(targetDir string) string {
 	return filepath.Join(filepath.Dir(cleanTarget), filepath.Base(cleanTarget)+".backups")
 }
 
+// stateDir 本工具在目标目录中保存状态文件的目录
+func stateDir(targetDir string) string {
+	return filepath.Join(targetDir, ".oh-my-rime")
+}
+
 func pruneBackups(targetDir string, keep int) error {
 	backupRoot := backupRootDir(targetDir)
 	entries, err := os.ReadDir(backupRoot)
@@ -256,7 +483,7 @@ func pruneBackups(targetDir string, keep int) error {
 	}
 
 	sort.Slice(dirs, func(i, j int) bool {
-		return dirs[i].Name() < dirs[j].Name()
+		return backupBefore(dirs[i].Name(), dirs[j].Name())
 	})
 
 	for _, entry := range dirs[:len(dirs)-keep] {
@@ -267,7 +494,32 @@ func pruneBackups(targetDir string, keep int) error {
 	return nil
 }
 
+// backupBefore 按创建顺序比较备份目录名。同一秒内的备份带有 -2、-3 等后缀，
+// 按数值比较后缀，避免 -10 排在 -2 之前
+func backupBefore(a, b string) bool {
+	baseA, seqA := splitBackupName(a)
+	baseB, seqB := splitBackupName(b)
+	if baseA != baseB {
+		return baseA < baseB
+	}
+	return seqA < seqB
+}
+
+// splitBackupName 拆分 uniqueBackupDir 生成的目录名，无后缀时序号为 1
+func splitBackupName(name string) (string, int) {
+	if i := strings.LastIndex(name, "-"); i > 0 && strings.Count(name, "-") > 1 {
+		if seq, err := strconv.Atoi(name[i+1:]); err == nil {
+			return name[:i], seq
+		}
+	}
+	return name, 1
+}
+
 func copyDir(src, dst string) error {
+	// 根目录本身是符号链接时复制其指向的内容，目录内的符号链接则不跟随
+	if realSrc, err := filepath.EvalSymlinks(src); err == nil {
+		src = realSrc
+	}
 	return filepath.WalkDir(src, func(path string, d os.DirEntry, walkErr error) error {
 		if walkErr != nil {
 			return walkErr
@@ -291,6 +543,10 @@ func copyDir(src, dst string) error {
 		if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
 			return err
 		}
+		if d.Type()&os.ModeSymlink != 0 {
+			// 符号链接按原样保存，不跟随（如指向网盘的 sync 目录）
+			return copySymlink(path, targetPath)
+		}
 		return copyFile(path, targetPath, info.Mode())
 	})
 }
@@ -317,7 +573,7 @@ func copyFile(src, dst string, mode os.FileMode) error {
 func safeJoin(baseDir, name string) (string, error) {
 	cleanName := filepath.Clean(name)
 	if filepath.IsAbs(cleanName) || cleanName == ".." || strings.HasPrefix(cleanName, ".."+string(os.PathSeparator)) {
-		return "", fmt.Errorf("zip 包含不安全路径: %s", name)
+		return "", invalidPackage("zip 包含不安全路径: %s", name)
 	}
 
 	targetPath := filepath.Join(baseDir, cleanName)
@@ -326,13 +582,52 @@ func safeJoin(baseDir, name string) (string, error) {
 		return "", err
 	}
 	if relPath == ".." || strings.HasPrefix(relPath, ".."+string(os.PathSeparator)) {
-		return "", fmt.Errorf("zip 包含不安全路径: %s", name)
+		return "", invalidPackage("zip 包含不安全路径: %s", name)
 	}
 	return targetPath, nil
 }
 
-// 辅助函数：解压单个文件
-func extractFile(file *zip.File, targetPath string) error {
+// prepareExtraction 检查文件名冲突、解压限制和磁盘空间，返回解压过程中使用的限额
+func prepareExtraction(files []*zip.File, targetDir string, opts Options) (*extractBudget, error) {
+	if err := checkNameCollisions(files, opts.AllowNameCollisions); err != nil {
+		return nil, err
+	}
+
+	limits := opts.extractLimits()
+	declaredSize, err := checkArchive(files, limits)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkFreeSpace(targetDir, declaredSize); err != nil {
+		return nil, err
+	}
+	return &extractBudget{limits: limits}, nil
+}
+
+// makeDir 在 rootDir 内创建目录并修正已有目录的权限
+func makeDir(rootDir, path string, mode os.FileMode) error {
+	if err := checkRealParent(rootDir, path); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(path, mode); err != nil {
+		return err
+	}
+	return os.Chmod(path, mode)
+}
+
+// 辅助函数：解压单个文件到 rootDir 内，按需创建父目录
+func extractFile(file *zip.File, rootDir, targetPath string, mode os.FileMode, budget *extractBudget) error {
+	// 创建父目录前后都检查真实路径，防止经由符号链接写到目标目录之外
+	if err := checkRealParent(rootDir, targetPath); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+		return fmt.Errorf("创建父目录失败: %v", err)
+	}
+	if err := checkRealParent(rootDir, targetPath); err != nil {
+		return err
+	}
+
 	// 打开zip文件中的文件
 	rc, err := file.Open()
 	if err != nil {
@@ -341,13 +636,23 @@ func extractFile(file *zip.File, targetPath string) error {
 	defer rc.Close()
 
 	// 创建目标文件（覆盖同名文件）
-	outFile, err := os.OpenFile(targetPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, file.FileInfo().Mode())
+	outFile, err := os.OpenFile(targetPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
 	if err != nil {
 		return err
 	}
-	defer outFile.Close()
 
-	// 复制文件内容
-	_, err = io.Copy(outFile, rc)
-	return err
+	// 复制文件内容，按实际写入字节数执行限制
+	if _, err := io.Copy(outFile, budget.limitReader(file, rc)); err != nil {
+		outFile.Close()
+		return err
+	}
+	if err := outFile.Close(); err != nil {
+		return err
+	}
+
+	// 覆盖已有文件时 OpenFile 不会修改权限，需要单独设置
+	if err := os.Chmod(targetPath, mode); err != nil {
+		return err
+	}
+	return applyModTime(file, targetPath)
 }
@@ -3,11 +3,27 @@ package updater
 import (
 	"archive/zip"
 	"bytes"
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 )
 
+func TestMain(m *testing.M) {
+	// 更新日志等状态文件写入临时目录，避免污染用户配置
+	configDir, err := os.MkdirTemp("", "oh-my-rime-test")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("OH_MY_RIME_CONFIG_DIR", configDir)
+
+	code := m.Run()
+	os.RemoveAll(configDir)
+	os.Exit(code)
+}
+
 func TestUpdateModelCreatesBackup(t *testing.T) {
 	parentDir := t.TempDir()
 	targetDir := filepath.Join(parentDir, "Rime")
@@ -19,13 +35,13 @@ func TestUpdateModelCreatesBackup(t *testing.T) {
 		t.Fatalf("write existing file: %v", err)
 	}
 
-	if err := UpdateModel([]byte("model"), targetDir); err != nil {
+	if err := UpdateModel(testGram("model"), targetDir); err != nil {
 		t.Fatalf("UpdateModel returned error: %v", err)
 	}
 
 	modelPath := filepath.Join(targetDir, "wanxiang-lts-zh-hans.gram")
-	if data, err := os.ReadFile(modelPath); err != nil || string(data) != "model" {
-		t.Fatalf("model file = %q, %v; want model", data, err)
+	if data, err := os.ReadFile(modelPath); err != nil || string(data) != string(testGram("model")) {
+		t.Fatalf("model file differs from download: %v", err)
 	}
 
 	backups, err := os.ReadDir(filepath.Join(parentDir, "Rime.backups"))
@@ -41,6 +57,24 @@ func TestUpdateModelCreatesBackup(t *testing.T) {
 	}
 }
 
+func TestUpdateModelNoBackup(t *testing.T) {
+	parentDir := t.TempDir()
+	targetDir := filepath.Join(parentDir, "Rime")
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		t.Fatalf("create target dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(targetDir, "default.custom.yaml"), []byte("old"), 0644); err != nil {
+		t.Fatalf("write existing file: %v", err)
+	}
+
+	if err := UpdateModelWithOptions(testGram("model"), targetDir, Options{NoBackup: true}); err != nil {
+		t.Fatalf("UpdateModelWithOptions returned error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(parentDir, "Rime.backups")); !os.IsNotExist(err) {
+		t.Fatalf("backup dir exists with NoBackup; stat error: %v", err)
+	}
+}
+
 func TestUpdateMainSchemeRejectsUnsafeZipAndRestoresBackup(t *testing.T) {
 	parentDir := t.TempDir()
 	targetDir := filepath.Join(parentDir, "Rime")
@@ -71,9 +105,91 @@ func TestUpdateMainSchemeRejectsUnsafeZipAndRestoresBackup(t *testing.T) {
 	}
 }
 
+func TestUpdateEmitsEventsAndMarksRollback(t *testing.T) {
+	targetDir := filepath.Join(t.TempDir(), "Rime")
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		t.Fatalf("create target dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(targetDir, "default.custom.yaml"), []byte("old"), 0644); err != nil {
+		t.Fatalf("write existing file: %v", err)
+	}
+
+	var events []string
+	opts := Options{Events: func(event Event) { events = append(events, event.Type) }}
+	err := UpdateMainSchemeWithOptions(testZip(t,
+		zipEntry{name: "new.yaml", body: "new"},
+		zipEntry{name: "../escape.yaml", body: "escape"},
+	), targetDir, opts)
+	if !errors.Is(err, ErrRolledBack) || !IsInvalidPackage(err) {
+		t.Fatalf("error = %v; want rolled back invalid package", err)
+	}
+	if err.Error() != "zip 包含不安全路径: ../escape.yaml" {
+		t.Fatalf("error message = %q", err.Error())
+	}
+	want := []string{EventBackupCreated, EventFileWritten, EventRolledBack}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("events = %v; want %v", events, want)
+	}
+}
+
+func TestCreateBackupUsesUniqueDirWithinSameSecond(t *testing.T) {
+	backupRoot := backupRootDir(filepath.Join(t.TempDir(), "Rime"))
+	if err := os.MkdirAll(backupRoot, 0755); err != nil {
+		t.Fatalf("create backup root: %v", err)
+	}
+
+	now := time.Now()
+	first, err := uniqueBackupDir(backupRoot, now)
+	if err != nil {
+		t.Fatalf("uniqueBackupDir returned error: %v", err)
+	}
+	second, err := uniqueBackupDir(backupRoot, now)
+	if err != nil {
+		t.Fatalf("uniqueBackupDir returned error: %v", err)
+	}
+	if first == second {
+		t.Fatalf("backup dirs are identical: %s", first)
+	}
+}
+
+func TestPruneBackupsKeepsNewestSameSecondBackups(t *testing.T) {
+	targetDir := filepath.Join(t.TempDir(), "Rime")
+	backupRoot := backupRootDir(targetDir)
+	if err := os.MkdirAll(backupRoot, 0755); err != nil {
+		t.Fatalf("create backup root: %v", err)
+	}
+	now := time.Date(2026, 10, 19, 12, 8, 16, 0, time.Local)
+	if _, err := uniqueBackupDir(backupRoot, now.Add(-time.Second)); err != nil {
+		t.Fatalf("uniqueBackupDir returned error: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		if _, err := uniqueBackupDir(backupRoot, now); err != nil {
+			t.Fatalf("uniqueBackupDir returned error: %v", err)
+		}
+	}
+
+	if err := pruneBackups(targetDir, 3); err != nil {
+		t.Fatalf("pruneBackups returned error: %v", err)
+	}
+	entries, err := os.ReadDir(backupRoot)
+	if err != nil {
+		t.Fatalf("read backup root: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	want := []string{"20261019-120816-10", "20261019-120816-8", "20261019-120816-9"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("kept backups = %v; want %v", names, want)
+	}
+}
+
 type zipEntry struct {
-	name string
-	body string
+	name     string
+	body     string
+	mode     os.FileMode
+	modified time.Time
 }
 
 func testZip(t *testing.T, entries ...zipEntry) []byte {
@@ -82,7 +198,11 @@ func testZip(t *testing.T, entries ...zipEntry) []byte {
 	var buf bytes.Buffer
 	zw := zip.NewWriter(&buf)
 	for _, entry := range entries {
-		w, err := zw.Create(entry.name)
+		header := &zip.FileHeader{Name: entry.name, Method: zip.Deflate, Modified: entry.modified}
+		if entry.mode != 0 {
+			header.SetMode(entry.mode)
+		}
+		w, err := zw.CreateHeader(header)
 		if err != nil {
 			t.Fatalf("create zip entry: %v", err)
 		}
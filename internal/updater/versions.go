@@ -0,0 +1,164 @@
+package updater
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// AssetVersion 目标目录中已安装资源的版本记录
+type AssetVersion struct {
+	// Source 下载地址
+	Source string `json:"source"`
+	// Version 发布版本号，下载地址为 latest 时从重定向得到，可能为空
+	Version string `json:"version,omitempty"`
+	ETag    string `json:"etag,omitempty"`
+	// SHA256 下载内容的哈希（词库为按需下载的部分内容）
+	SHA256      string    `json:"sha256"`
+	Size        int64     `json:"size"`
+	ReleaseDate time.Time `json:"releaseDate,omitempty"`
+	InstalledAt time.Time `json:"installedAt"`
+}
+
+// ModelAsset 返回模型在版本记录中的名称，不同文件名的模型分别记录
+func ModelAsset(modelName string) string {
+	return OperationModel + ":" + modelName
+}
+
+// versionsPath 版本记录保存在目标目录下，随目标目录一起备份和恢复
+func versionsPath(targetDir string) string {
+	return filepath.Join(stateDir(targetDir), "versions.json")
+}
+
+// InstalledVersions 读取目标目录中各资源的版本记录，键为 OperationMainScheme、OperationDict 或 ModelAsset
+func InstalledVersions(targetDir string) (map[string]AssetVersion, error) {
+	data, err := os.ReadFile(versionsPath(targetDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]AssetVersion{}, nil
+		}
+		return nil, err
+	}
+	versions := map[string]AssetVersion{}
+	if err := json.Unmarshal(data, &versions); err != nil {
+		return nil, fmt.Errorf("版本记录格式错误: %v", err)
+	}
+	return versions, nil
+}
+
+func saveInstalledVersion(targetDir, asset string, version AssetVersion) error {
+	versions, err := InstalledVersions(targetDir)
+	if err != nil {
+		// 损坏的记录直接覆盖
+		versions = map[string]AssetVersion{}
+	}
+	versions[asset] = version
+
+	path := versionsPath(targetDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(versions, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// recordVersion 更新成功后记录资源版本，记录失败只给出警告
+func (o Options) recordVersion(targetDir, asset string, data []byte) {
+	sum := sha256.Sum256(data)
+	version := AssetVersion{
+		Source:      o.Source,
+		Version:     o.version(),
+		ETag:        o.ETag,
+		SHA256:      hex.EncodeToString(sum[:]),
+		Size:        int64(len(data)),
+		ReleaseDate: o.ReleaseDate,
+		InstalledAt: time.Now(),
+	}
+	if version.Version == "latest" {
+		version.Version = ""
+	}
+	if err := saveInstalledVersion(targetDir, asset, version); err != nil {
+		fmt.Printf("⚠️  记录版本信息失败: %v\n", err)
+	}
+}
+
+// UpdateStatus 已安装资源与最新发布的比较结果
+type UpdateStatus string
+
+const (
+	StatusUpToDate        UpdateStatus = "up-to-date"
+	StatusUpdateAvailable UpdateStatus = "update-available"
+	// StatusUnknown 没有可比较的 ETag 或版本号（如旧版本安装的资源）
+	StatusUnknown      UpdateStatus = "unknown"
+	StatusNotInstalled UpdateStatus = "not-installed"
+)
+
+// CompareVersion 比较已安装的版本与远程文件：ETag 最可靠，其次是发布版本号
+func CompareVersion(installed *AssetVersion, version, etag string) UpdateStatus {
+	switch {
+	case installed == nil:
+		return StatusNotInstalled
+	case installed.ETag != "" && etag != "":
+		if installed.ETag == etag {
+			return StatusUpToDate
+		}
+		return StatusUpdateAvailable
+	case installed.Version != "" && version != "":
+		if installed.Version == version {
+			return StatusUpToDate
+		}
+		return StatusUpdateAvailable
+	}
+	return StatusUnknown
+}
+
+// pinsPath 固定版本的记录，与版本记录放在一起
+func pinsPath(targetDir string) string {
+	return filepath.Join(stateDir(targetDir), "pins.json")
+}
+
+// Pins 读取目标目录中固定的版本，键与 InstalledVersions 相同，值为发布版本号
+func Pins(targetDir string) (map[string]string, error) {
+	data, err := os.ReadFile(pinsPath(targetDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+	pins := map[string]string{}
+	if err := json.Unmarshal(data, &pins); err != nil {
+		return nil, fmt.Errorf("固定版本记录格式错误: %v", err)
+	}
+	return pins, nil
+}
+
+// SetPin 把资源固定在 tag 版本，tag 为空时解除固定
+func SetPin(targetDir, asset, tag string) error {
+	pins, err := Pins(targetDir)
+	if err != nil {
+		return err
+	}
+	if tag == "" {
+		delete(pins, asset)
+	} else {
+		pins[asset] = tag
+	}
+
+	path := pinsPath(targetDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(pins, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
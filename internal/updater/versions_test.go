@@ -0,0 +1,82 @@
+package updater
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUpdateRecordsInstalledVersions(t *testing.T) {
+	targetDir := t.TempDir()
+	released := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	err := UpdateModelWithOptions(testGram("model"), targetDir, Options{
+		Source:      "https://example.com/releases/download/latest/wanxiang-lts-zh-hans.gram",
+		ModelName:   "wanxiang-lts-zh-hans.gram",
+		ETag:        `"model-etag"`,
+		ReleaseDate: released,
+		NoBackup:    true,
+	})
+	if err != nil {
+		t.Fatalf("UpdateModelWithOptions returned error: %v", err)
+	}
+	err = UpdateMainSchemeWithOptions(testZip(t, zipEntry{name: "default.yaml", body: "new"}), targetDir, Options{
+		Source:   "https://example.com/releases/download/v1.2.3/oh-my-rime.zip",
+		NoBackup: true,
+	})
+	if err != nil {
+		t.Fatalf("UpdateMainSchemeWithOptions returned error: %v", err)
+	}
+
+	versions, err := InstalledVersions(targetDir)
+	if err != nil {
+		t.Fatalf("InstalledVersions returned error: %v", err)
+	}
+	model, ok := versions[ModelAsset("wanxiang-lts-zh-hans.gram")]
+	if !ok || model.ETag != `"model-etag"` || model.Version != "" || !model.ReleaseDate.Equal(released) || model.SHA256 == "" {
+		t.Errorf("model version = %+v", model)
+	}
+	if scheme := versions[OperationMainScheme]; scheme.Version != "v1.2.3" || scheme.Size == 0 {
+		t.Errorf("scheme version = %+v", scheme)
+	}
+}
+
+func TestCompareVersion(t *testing.T) {
+	tests := []struct {
+		installed     *AssetVersion
+		version, etag string
+		want          UpdateStatus
+	}{
+		{nil, "v1", `"a"`, StatusNotInstalled},
+		{&AssetVersion{ETag: `"a"`, Version: "v1"}, "v2", `"a"`, StatusUpToDate},
+		{&AssetVersion{ETag: `"a"`}, "", `"b"`, StatusUpdateAvailable},
+		{&AssetVersion{Version: "v1"}, "v1", "", StatusUpToDate},
+		{&AssetVersion{Version: "v1"}, "v2", `"b"`, StatusUpdateAvailable},
+		{&AssetVersion{SHA256: "abc"}, "", `"b"`, StatusUnknown},
+	}
+	for _, tt := range tests {
+		if got := CompareVersion(tt.installed, tt.version, tt.etag); got != tt.want {
+			t.Errorf("CompareVersion(%+v, %q, %q) = %s; want %s", tt.installed, tt.version, tt.etag, got, tt.want)
+		}
+	}
+}
+
+func TestSetPin(t *testing.T) {
+	targetDir := t.TempDir()
+	if err := SetPin(targetDir, OperationMainScheme, "v1.2.0"); err != nil {
+		t.Fatalf("SetPin returned error: %v", err)
+	}
+	if err := SetPin(targetDir, ModelAsset("a.gram"), "LTS"); err != nil {
+		t.Fatalf("SetPin returned error: %v", err)
+	}
+	if err := SetPin(targetDir, ModelAsset("a.gram"), ""); err != nil {
+		t.Fatalf("SetPin returned error: %v", err)
+	}
+
+	pins, err := Pins(targetDir)
+	if err != nil {
+		t.Fatalf("Pins returned error: %v", err)
+	}
+	if len(pins) != 1 || pins[OperationMainScheme] != "v1.2.0" {
+		t.Fatalf("unexpected pins: %v", pins)
+	}
+}